@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/Harsh-BH/Exo/internal/config"
 	"github.com/charmbracelet/lipgloss"
@@ -33,8 +34,8 @@ var addCmd = &cobra.Command{
 	Long: `Add individual DevOps tools to an existing project without re-running the full wizard.
 
 Available tools:
-  monitoring   Prometheus + Grafana stack
-  ci           CI/CD pipeline (GitHub Actions or GitLab CI)
+  monitoring   Monitoring stack from .exo.yaml (default Prometheus + Grafana)
+  ci           CI/CD pipeline (--ci picks GitHub Actions, GitLab CI, Jenkins, CircleCI, ...)
   k8s          Kubernetes manifests (deployment, service, ingress)
   infra        Terraform infrastructure for a cloud provider
   db           Database docker-compose`,
@@ -67,22 +68,26 @@ Available tools:
 	},
 }
 
+// addMonitoring writes the stack .exo.yaml lists, or Prometheus + Grafana.
 func addMonitoring(cwd, name string) {
-	monDir := filepath.Join(cwd, "monitoring")
-	files := map[string]string{
-		"prometheus.yml":                filepath.Join("templates", "monitoring", "prometheus.tmpl"),
-		"docker-compose.monitoring.yml": filepath.Join("templates", "monitoring", "docker-compose.monitoring.tmpl"),
-	}
-	data := struct{ AppName string }{AppName: name}
-	allOK := true
-	for outFile, tmplPath := range files {
-		if err := renderFile(tmplPath, filepath.Join(monDir, outFile), data, false, false); err != nil {
-			addPrintErr(fmt.Sprintf("monitoring/%s: %v", outFile, err))
-			allOK = false
+	data := config.TemplateData{AppName: name, Port: 8080}
+	if cfg, err := config.Load(cwd); err == nil {
+		data.Monitoring = cfg.Monitoring
+		if cfg.Port != 0 {
+			data.Port = cfg.Port
 		}
 	}
-	if allOK {
-		addPrintOK("Prometheus + Grafana → monitoring/")
+	if !data.Monitoring.Enabled() {
+		data.Monitoring.Metrics = "prometheus"
+	}
+	files, err := monitoringFiles(cwd, data)
+	if err == nil {
+		err = renderFiles(cwd, files, false, false)
+	}
+	if err != nil {
+		addPrintErr(fmt.Sprintf("monitoring: %v", err))
+	} else {
+		addPrintOK(monitoringLabel(data.Monitoring) + " → monitoring/")
 	}
 }
 
@@ -91,32 +96,19 @@ func addCI(cwd, name string, cmd *cobra.Command) {
 	if ciTool == "" {
 		ciTool = "github-actions"
 	}
-	data := struct{ AppName string }{AppName: name}
-	switch ciTool {
-	case "github-actions":
-		ciDir := filepath.Join(cwd, ".github", "workflows")
-		if err := renderFile(filepath.Join("templates", "ci", "github-actions.tmpl"), filepath.Join(ciDir, "go.yml"), data, false, false); err != nil {
-			addPrintErr(fmt.Sprintf("GitHub Actions: %v", err))
-		} else {
-			addPrintOK("GitHub Actions → .github/workflows/go.yml")
-		}
-	case "gitlab-ci":
-		if err := renderFile(filepath.Join("templates", "ci", "gitlab-ci.tmpl"), filepath.Join(cwd, ".gitlab-ci.yml"), data, false, false); err != nil {
-			addPrintErr(fmt.Sprintf("GitLab CI: %v", err))
-		} else {
-			addPrintOK("GitLab CI → .gitlab-ci.yml")
-		}
-	default:
-		addPrintErr(fmt.Sprintf("Unknown CI tool: %s (use github-actions or gitlab-ci)", ciTool))
+	data := newCIData(cwd, config.TemplateData{AppName: name, Language: detectLang(cwd), CI: ciTool, Port: 8080})
+	if written, err := writeCI(cwd, data, false, false); err != nil {
+		addPrintErr(fmt.Sprintf("%s: %v", ciLabel(ciTool), err))
+	} else {
+		addPrintOK(fmt.Sprintf("%s → %s", ciLabel(ciTool), strings.Join(written, ", ")))
 	}
 }
 
 func addK8s(cwd, name string) {
 	k8sDir := filepath.Join(cwd, "k8s")
-	data := struct{ AppName string }{AppName: name}
-	files := []string{"deployment.yaml", "service.yaml", "ingress.yaml"}
+	data := config.TemplateData{AppName: name}
 	allOK := true
-	for _, f := range files {
+	for _, f := range k8sManifests {
 		if err := renderFile(filepath.Join("templates", "k8s", f+".tmpl"), filepath.Join(k8sDir, f), data, false, false); err != nil {
 			addPrintErr(fmt.Sprintf("k8s/%s: %v", f, err))
 			allOK = false
@@ -133,12 +125,14 @@ func addInfra(cwd, name string, cmd *cobra.Command) error {
 		return fmt.Errorf("--provider flag required (aws, gcp, azure)")
 	}
 	infraDir := filepath.Join(cwd, "infra", p)
-	tmplDir := filepath.Join("templates", "terraform", p)
-	data := struct{ AppName string }{AppName: name}
+	files, err := infraFiles(filepath.Join("terraform", p), infraDir, newInfraData(cwd, config.TemplateData{AppName: name, Provider: p, Port: 8080}))
+	if err != nil {
+		return err
+	}
 	allOK := true
-	for _, f := range []string{"main.tf", "variables.tf", "provider.tf"} {
-		if err := renderFile(filepath.Join(tmplDir, f+".tmpl"), filepath.Join(infraDir, f), data, false, false); err != nil {
-			addPrintErr(fmt.Sprintf("infra/%s/%s: %v", p, f, err))
+	for _, f := range files {
+		if err := renderFile(filepath.Join("templates", f.tmpl), f.out, f.data, false, false); err != nil {
+			addPrintErr(fmt.Sprintf("infra/%s/%s: %v", p, filepath.Base(f.out), err))
 			allOK = false
 		}
 	}
@@ -153,19 +147,11 @@ func addDB(cwd, name string, cmd *cobra.Command) error {
 	if db == "" {
 		return fmt.Errorf("--db flag required (postgres, mysql, mongo, redis)")
 	}
-	tmplMap := map[string]string{
-		"postgres": "postgres.tmpl",
-		"mysql":    "mysql.tmpl",
-		"mongo":    "mongo.tmpl",
-		"redis":    "redis.tmpl",
-	}
-	tmplFile, ok := tmplMap[db]
-	if !ok {
-		return fmt.Errorf("unknown database: %s (use postgres, mysql, mongo, redis)", db)
+	f, err := dbComposeFile(cwd, config.TemplateData{AppName: name, DB: db})
+	if err != nil {
+		return err
 	}
-	data := struct{ AppName string }{AppName: name}
-	outPath := filepath.Join(cwd, fmt.Sprintf("docker-compose.%s.yml", db))
-	if err := renderFile(filepath.Join("templates", "db", tmplFile), outPath, data, false, false); err != nil {
+	if err := renderFiles(cwd, []genFile{f}, false, false); err != nil {
 		addPrintErr(fmt.Sprintf("db: %v", err))
 	} else {
 		addPrintOK(fmt.Sprintf("%s → docker-compose.%s.yml", db, db))
@@ -174,7 +160,7 @@ func addDB(cwd, name string, cmd *cobra.Command) error {
 }
 
 func init() {
-	addCmd.Flags().String("ci", "github-actions", "CI tool to add (github-actions, gitlab-ci)")
+	addCmd.Flags().String("ci", "github-actions", "CI tool to add (github-actions, gitlab-ci, jenkins, circleci, azure-pipelines, bitbucket-pipelines, woodpecker)")
 	addCmd.Flags().String("provider", "", "Cloud provider for infra (aws, gcp, azure)")
 	addCmd.Flags().String("db", "", "Database to add (postgres, mysql, mongo, redis)")
 	rootCmd.AddCommand(addCmd)
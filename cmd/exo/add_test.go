@@ -77,3 +77,25 @@ func TestAddDB(t *testing.T) {
 		t.Errorf("expected docker-compose.postgres.yml to exist: %v", err)
 	}
 }
+
+func TestAddCI(t *testing.T) {
+	dir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := &cobra.Command{}
+	cmd.Flags().String("ci", "github-actions", "")
+
+	addCI(dir, "testapp", cmd)
+
+	for _, f := range []string{"ci.yml", "security.yml"} {
+		if _, err := os.Stat(filepath.Join(dir, ".github", "workflows", f)); err != nil {
+			t.Errorf("expected .github/workflows/%s to exist: %v", f, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".github", "workflows", "go.yml")); err == nil {
+		t.Error("expected no go.yml for a node project")
+	}
+}
@@ -14,7 +14,9 @@ import (
 // Relative to the project root.
 var knownGeneratedFiles = []string{
 	"Dockerfile",
+	".dockerignore",
 	"docker-compose.yml",
+	"compose.override.yml",
 	"docker-compose.postgres.yml",
 	"docker-compose.mongo.yml",
 	"docker-compose.mysql.yml",
@@ -29,12 +31,27 @@ var knownGeneratedFiles = []string{
 	"LICENSE",
 	"README.md",
 	".pre-commit-config.yaml",
+	".gitlab-ci.yml",
+	"Jenkinsfile",
+	"azure-pipelines.yml",
+	"bitbucket-pipelines.yml",
+	".woodpecker.yml",
+	".goreleaser.yaml",
+	"Dockerfile.goreleaser",
+	".releaserc.json",
+	"release-please-config.json",
+	".changeset/config.json",
+	"Tiltfile",
+	"skaffold.yaml",
 	// directories
 	"k8s/",
 	"charts/",
+	"gitops/",
+	"devloop/",
 	"infra/",
 	"monitoring/",
 	".devcontainer/",
+	".circleci/",
 	".github/dependabot.yml",
 	".github/workflows/",
 }
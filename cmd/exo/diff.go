@@ -7,7 +7,6 @@ import (
 	"path/filepath"
 	"text/template"
 
-	"github.com/Harsh-BH/Exo/internal/config"
 	"github.com/Harsh-BH/Exo/internal/detector"
 	"github.com/Harsh-BH/Exo/templates"
 	"github.com/spf13/cobra"
@@ -31,22 +30,51 @@ Types are the same as 'exo gen'. Example:
 			return err
 		}
 
-		data := loadTemplateData(cmd, cwd)
+		data, err := loadTemplateData(cmd, cwd)
+		if err != nil {
+			return err
+		}
 
 		var outPath, tmplPath string
 		switch genType {
 		case "docker":
-			outPath = filepath.Join(cwd, "Dockerfile")
-			tmplPath = diffPickDockerTmpl(data.Language)
+			d, err := newDockerData(cwd, data)
+			if err != nil {
+				return err
+			}
+			for _, f := range dockerFiles(cwd, d) {
+				if err := diffFile(filepath.Join("templates", f.tmpl), f.out, f.data); err != nil {
+					return err
+				}
+			}
+			return nil
 		case "ci":
-			outPath = diffCIPath(cwd, data)
-			tmplPath = filepath.Join("templates", "ci", "github-actions.tmpl")
+			if data.CI == "" || data.CI == "none" {
+				data.CI = "github-actions"
+			}
+			for _, f := range ciFiles(cwd, newCIData(cwd, data)) {
+				if err := diffFile(filepath.Join("templates", f.tmpl), f.out, f.data); err != nil {
+					return err
+				}
+			}
+			return nil
+		case "docker-compose":
+			files, err := composeFiles(cwd, data, fileExists(filepath.Join(cwd, "compose.override.yml")))
+			if err != nil {
+				return err
+			}
+			for _, f := range files {
+				if err := diffFile(filepath.Join("templates", f.tmpl), f.out, f.data); err != nil {
+					return err
+				}
+			}
+			return nil
 		case "k8s":
 			outPath = filepath.Join(cwd, "k8s", "deployment.yaml")
 			tmplPath = filepath.Join("templates", "k8s", "deployment.yaml.tmpl")
 		case "makefile":
-			outPath = filepath.Join(cwd, "Makefile")
-			tmplPath = filepath.Join("templates", "makefile", "Makefile.tmpl")
+			f := makefileFile(cwd, data)
+			return diffFile(filepath.Join("templates", f.tmpl), f.out, f.data)
 		case "gitignore":
 			outPath = filepath.Join(cwd, ".gitignore")
 			tmplPath = filepath.Join("templates", "gitignore", "gitignore.tmpl")
@@ -57,28 +85,33 @@ Types are the same as 'exo gen'. Example:
 			return fmt.Errorf("diff not supported for type: %s", genType)
 		}
 
-		generated, err := renderToString(tmplPath, data)
-		if err != nil {
-			return fmt.Errorf("render: %w", err)
-		}
+		return diffFile(tmplPath, outPath, data)
+	},
+}
 
-		existing, readErr := os.ReadFile(outPath)
-		if readErr != nil {
-			fmt.Printf("\033[1;32m+++ (new file) %s\033[0m\n", filepath.Base(outPath))
-			for _, line := range bytes.Split([]byte(generated), []byte("\n")) {
-				fmt.Printf("\033[32m+ %s\033[0m\n", line)
-			}
-			return nil
-		}
+// diffFile renders tmplPath and prints how it differs from outPath.
+func diffFile(tmplPath, outPath string, data interface{}) error {
+	generated, err := renderToString(tmplPath, data)
+	if err != nil {
+		return fmt.Errorf("render: %w", err)
+	}
 
-		if string(existing) == generated {
-			fmt.Printf("\033[32m✓  %s is up-to-date — no changes\033[0m\n", filepath.Base(outPath))
-			return nil
+	existing, readErr := os.ReadFile(outPath)
+	if readErr != nil {
+		fmt.Printf("\033[1;32m+++ (new file) %s\033[0m\n", filepath.Base(outPath))
+		for _, line := range bytes.Split([]byte(generated), []byte("\n")) {
+			fmt.Printf("\033[32m+ %s\033[0m\n", line)
 		}
+		return nil
+	}
 
-		printLineDiff(filepath.Base(outPath), string(existing), generated)
+	if string(existing) == generated {
+		fmt.Printf("\033[32m✓  %s is up-to-date — no changes\033[0m\n", filepath.Base(outPath))
 		return nil
-	},
+	}
+
+	printLineDiff(filepath.Base(outPath), string(existing), generated)
+	return nil
 }
 
 // renderToString renders a template file into a string (no file written).
@@ -102,26 +135,6 @@ func renderToString(tmplPath string, data interface{}) (string, error) {
 	return buf.String(), nil
 }
 
-func diffPickDockerTmpl(lang string) string {
-	switch lang {
-	case "node":
-		return filepath.Join("templates", "docker", "node.tmpl")
-	case "python":
-		return filepath.Join("templates", "docker", "python.tmpl")
-	default:
-		return filepath.Join("templates", "docker", "dockerfile.tmpl")
-	}
-}
-
-func diffCIPath(cwd string, data config.TemplateData) string {
-	dir := filepath.Join(cwd, ".github", "workflows")
-	name := "ci.yml"
-	if data.Language != "" && data.Language != "go" {
-		name = data.Language + "-ci.yml"
-	}
-	return filepath.Join(dir, name)
-}
-
 // printLineDiff prints a coloured +/- line diff between old and new.
 func printLineDiff(filename, old, new string) {
 	oldLines := bytes.Split([]byte(old), []byte("\n"))
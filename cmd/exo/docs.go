@@ -10,20 +10,25 @@ import (
 )
 
 var docsURLs = map[string]string{
-	"":               "https://github.com/Harsh-BH/Exo#readme",
-	"exo":            "https://github.com/Harsh-BH/Exo#readme",
-	"terraform":      "https://developer.hashicorp.com/terraform/docs",
-	"k8s":            "https://kubernetes.io/docs/home/",
-	"kubernetes":     "https://kubernetes.io/docs/home/",
-	"helm":           "https://helm.sh/docs/",
-	"docker":         "https://docs.docker.com/",
-	"github-actions": "https://docs.github.com/en/actions",
-	"gitlab-ci":      "https://docs.gitlab.com/ee/ci/",
-	"prometheus":     "https://prometheus.io/docs/",
-	"grafana":        "https://grafana.com/docs/",
-	"aws":            "https://docs.aws.amazon.com/",
-	"gcp":            "https://cloud.google.com/docs",
-	"azure":          "https://learn.microsoft.com/en-us/azure/",
+	"":                    "https://github.com/Harsh-BH/Exo#readme",
+	"exo":                 "https://github.com/Harsh-BH/Exo#readme",
+	"terraform":           "https://developer.hashicorp.com/terraform/docs",
+	"k8s":                 "https://kubernetes.io/docs/home/",
+	"kubernetes":          "https://kubernetes.io/docs/home/",
+	"helm":                "https://helm.sh/docs/",
+	"docker":              "https://docs.docker.com/",
+	"github-actions":      "https://docs.github.com/en/actions",
+	"gitlab-ci":           "https://docs.gitlab.com/ee/ci/",
+	"jenkins":             "https://www.jenkins.io/doc/book/pipeline/",
+	"circleci":            "https://circleci.com/docs/",
+	"azure-pipelines":     "https://learn.microsoft.com/en-us/azure/devops/pipelines/",
+	"bitbucket-pipelines": "https://support.atlassian.com/bitbucket-cloud/docs/get-started-with-bitbucket-pipelines/",
+	"woodpecker":          "https://woodpecker-ci.org/docs/intro",
+	"prometheus":          "https://prometheus.io/docs/",
+	"grafana":             "https://grafana.com/docs/",
+	"aws":                 "https://docs.aws.amazon.com/",
+	"gcp":                 "https://cloud.google.com/docs",
+	"azure":               "https://learn.microsoft.com/en-us/azure/",
 }
 
 var docsCmd = &cobra.Command{
@@ -39,6 +44,11 @@ Available topics:
   docker          Docker docs
   github-actions  GitHub Actions docs
   gitlab-ci       GitLab CI docs
+  jenkins         Jenkins pipeline docs
+  circleci        CircleCI docs
+  azure-pipelines Azure Pipelines docs
+  bitbucket-pipelines Bitbucket Pipelines docs
+  woodpecker      Woodpecker CI docs
   prometheus      Prometheus docs
   grafana         Grafana docs
   aws             AWS docs
@@ -53,7 +63,7 @@ Available topics:
 
 		url, ok := docsURLs[topic]
 		if !ok {
-			return fmt.Errorf("unknown topic: %s\n\nAvailable topics: terraform, k8s, helm, docker, github-actions, gitlab-ci, prometheus, grafana, aws, gcp, azure", topic)
+			return fmt.Errorf("unknown topic: %s\n\nAvailable topics: terraform, k8s, helm, docker, github-actions, gitlab-ci, jenkins, circleci, azure-pipelines, bitbucket-pipelines, woodpecker, prometheus, grafana, aws, gcp, azure", topic)
 		}
 
 		infoStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
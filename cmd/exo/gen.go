@@ -27,13 +27,61 @@ func renderFile(tmplPath, outPath string, data interface{}, dryRun, force bool)
 	return renderer.RenderTemplate(tmplPath, outPath, data)
 }
 
+// writeFile writes pre-rendered content to outPath with the same --dry-run
+// and --force semantics as renderFile.
+func writeFile(outPath string, content []byte, dryRun, force bool) error {
+	if dryRun {
+		fmt.Printf("  [dry-run] would write → %s\n", outPath)
+		return nil
+	}
+	if !force {
+		if _, err := os.Stat(outPath); err == nil {
+			fmt.Printf("  ⚠ %s already exists (use --force to overwrite)\n", filepath.Base(outPath))
+			return nil
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(outPath), err)
+	}
+	return os.WriteFile(outPath, content, 0o644)
+}
+
+// genFile is one template → output mapping. tmpl is relative to templates/.
+type genFile struct {
+	tmpl string
+	out  string
+	data interface{}
+}
+
+// renderFiles renders files in order, stopping at the first failure. Errors
+// are prefixed with the output path relative to cwd.
+func renderFiles(cwd string, files []genFile, dryRun, force bool) error {
+	for _, f := range files {
+		tmpl := filepath.Join("templates", f.tmpl)
+		if err := renderFile(tmpl, f.out, f.data, dryRun, force); err != nil {
+			rel, _ := filepath.Rel(cwd, f.out)
+			return fmt.Errorf("%s: %w", filepath.ToSlash(rel), err)
+		}
+	}
+	return nil
+}
+
 // loadTemplateData builds a TemplateData from flags, falling back to .exo.yaml,
-// then to the auto-detector.
-func loadTemplateData(cmd *cobra.Command, cwd string) config.TemplateData {
+// then to the auto-detector. A .exo.yaml that fails to parse is an error
+// rather than a silent fallback to detected defaults.
+func loadTemplateData(cmd *cobra.Command, cwd string) (config.TemplateData, error) {
 	// Try loading persisted config first
 	var base config.TemplateData
-	if cfg, err := config.Load(cwd); err == nil {
+	if config.Exists(cwd) {
+		cfg, err := config.Load(cwd)
+		if err != nil {
+			return base, err
+		}
 		base = cfg.ToTemplateData()
+		// Older configs don't record the framework; detect it for the same language.
+		if info, err := detector.Detect(cwd); err == nil && base.Framework == "" && info.Language == base.Language {
+			base.Framework = info.Framework
+		}
 	} else {
 		// Auto-detect language/framework if no config
 		if info, err := detector.Detect(cwd); err == nil {
@@ -44,6 +92,9 @@ func loadTemplateData(cmd *cobra.Command, cwd string) config.TemplateData {
 	if base.Port == 0 {
 		base.Port = 8080
 	}
+	if len(base.Environments) == 0 {
+		base.Environments = config.DefaultEnvironments
+	}
 
 	// Flag overrides beat .exo.yaml
 	if cmd.Flags().Changed("name") {
@@ -67,9 +118,32 @@ func loadTemplateData(cmd *cobra.Command, cwd string) config.TemplateData {
 	}
 	if cmd.Flags().Changed("monitoring") {
 		v, _ := cmd.Flags().GetString("monitoring")
-		base.Monitoring = v
+		if m, err := config.ParseMonitoring(v); err != nil {
+			fmt.Printf("  ⚠  --monitoring: %v; keeping %s\n", err, base.Monitoring)
+		} else {
+			base.Monitoring = m
+		}
 	}
-	return base
+	if cmd.Flags().Changed("ci") {
+		v, _ := cmd.Flags().GetString("ci")
+		base.CI = v
+	}
+	if cmd.Flags().Changed("compute") {
+		v, _ := cmd.Flags().GetString("compute")
+		base.Compute = v
+	}
+	if cmd.Flags().Changed("strategy") {
+		v, _ := cmd.Flags().GetString("strategy")
+		base.Strategy = v
+	}
+	if cmd.Flags().Changed("domain") {
+		v, _ := cmd.Flags().GetString("domain")
+		base.Domain = v
+	}
+	if base.Domain != "" && base.ACMEEmail == "" {
+		base.ACMEEmail = "admin@" + base.Domain
+	}
+	return base, nil
 }
 
 var genCmd = &cobra.Command{
@@ -78,21 +152,25 @@ var genCmd = &cobra.Command{
 	Long: `Generate a specific DevOps asset for your project.
 
 Types:
-  docker          Dockerfile (language-aware)
+  docker          Dockerfile + .dockerignore (language-aware; hardened via docker: in .exo.yaml)
+  dockerignore    .dockerignore for the detected stack
   infra           Terraform infrastructure
-  k8s             Kubernetes manifests
-  helm            Helm chart
-  ci              CI/CD pipeline
+  k8s             Kubernetes manifests (--format kustomize for base + overlays)
+  helm            Helm chart (--with-deps adds DB / monitoring subcharts)
+  gitops          Argo CD Applications or Flux objects per environment (--tool argocd|flux)
+  ci              CI/CD pipeline (--deploy adds OIDC deploy jobs per environment)
+  devloop         Local kind/k3d cluster + registry with a Tiltfile or skaffold.yaml (--tool tilt|skaffold, --cluster kind|k3d)
+  release         Release tooling + tag-triggered publish workflow (--tool goreleaser|semantic-release|changesets|release-please)
   db              Database docker-compose
   makefile        Makefile
   env             .env.example
   gitignore       .gitignore
   grafana         Grafana dashboard JSON
-  alerts          Prometheus alert rules
-  docker-compose  Full docker-compose.yml (app + db + monitoring)
+  alerts          Alert rules + Alertmanager routing to the alerts.receivers
+  docker-compose  docker-compose.yml with app, db and monitoring profiles (--override adds hot reload)
   readme          README.md
   pre-commit      .pre-commit-config.yaml
-  devcontainer    .devcontainer/devcontainer.json
+  devcontainer    .devcontainer/ on compose with the db and monitoring services
   renovate        renovate.json
   license         LICENSE file
   dependabot      .github/dependabot.yml
@@ -119,7 +197,10 @@ Types:
 
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
 		force, _ := cmd.Flags().GetBool("force")
-		data := loadTemplateData(cmd, cwd)
+		data, err := loadTemplateData(cmd, cwd)
+		if err != nil {
+			return err
+		}
 
 		if dryRun {
 			fmt.Println("  [dry-run mode — no files will be written]")
@@ -128,14 +209,29 @@ Types:
 		switch genType {
 		case "docker":
 			return generateDockerfile(cwd, data, dryRun, force)
+		case "dockerignore":
+			return generateDockerignore(cwd, data, dryRun, force)
 		case "infra":
 			return generateInfra(cwd, data, dryRun, force)
 		case "k8s":
-			return generateK8s(cwd, data, dryRun, force)
+			format, _ := cmd.Flags().GetString("format")
+			return generateK8s(cwd, data, format, dryRun, force)
 		case "helm":
-			return generateHelm(cwd, data, dryRun, force)
+			withDeps, _ := cmd.Flags().GetBool("with-deps")
+			return generateHelm(cwd, data, withDeps, dryRun, force)
+		case "gitops":
+			tool, _ := cmd.Flags().GetString("tool")
+			return generateGitOps(cwd, data, tool, dryRun, force)
+		case "devloop":
+			tool, _ := cmd.Flags().GetString("tool")
+			cluster, _ := cmd.Flags().GetString("cluster")
+			return generateDevloop(cwd, data, tool, cluster, dryRun, force)
+		case "release":
+			tool, _ := cmd.Flags().GetString("tool")
+			return generateRelease(cwd, data, tool, dryRun, force)
 		case "ci":
-			return generateCI(cwd, data, dryRun, force)
+			deploy, _ := cmd.Flags().GetBool("deploy")
+			return generateCI(cwd, data, deploy, dryRun, force)
 		case "db":
 			return generateDB(cwd, data, dryRun, force)
 		case "makefile":
@@ -149,7 +245,8 @@ Types:
 		case "alerts":
 			return generateAlerts(cwd, data, dryRun, force)
 		case "docker-compose":
-			return generateDockerCompose(cwd, data, dryRun, force)
+			override, _ := cmd.Flags().GetBool("override")
+			return generateDockerCompose(cwd, data, override, dryRun, force)
 		case "readme":
 			return generateReadme(cwd, data, dryRun, force)
 		case "pre-commit":
@@ -179,9 +276,19 @@ func init() {
 	genCmd.Flags().StringP("lang", "l", "", "Language override (go, node, python, java, rust)")
 	genCmd.Flags().StringP("provider", "p", "", "Cloud provider override (aws, gcp, azure)")
 	genCmd.Flags().String("db", "", "Database override (postgres, mysql, mongo, redis)")
-	genCmd.Flags().String("monitoring", "", "Monitoring override (prometheus, none)")
+	genCmd.Flags().String("monitoring", "", "Monitoring override: none, or a comma-separated set of prometheus|victoriametrics, loki, tempo|jaeger, otel-collector")
+	genCmd.Flags().String("ci", "", "CI system override for 'exo gen ci' (github-actions, gitlab-ci, jenkins, circleci, azure-pipelines, bitbucket-pipelines, woodpecker)")
+	genCmd.Flags().String("compute", "", "Compute target for 'exo gen infra' (kubernetes, ecs-fargate, app-runner, lambda-container, cloud-run, container-apps)")
+	genCmd.Flags().String("strategy", "", "Rollout strategy override for k8s/helm (rolling, canary, blue-green)")
+	genCmd.Flags().String("domain", "", "DNS zone the app is served under; enables DNS, ingress-nginx and cert-manager TLS")
 	genCmd.Flags().Bool("dry-run", false, "Preview what would be generated without writing files")
 	genCmd.Flags().Bool("force", false, "Overwrite existing files without prompting")
 	genCmd.Flags().String("license-type", "mit", "License type for 'exo gen license' (mit, apache2, gpl3)")
+	genCmd.Flags().String("format", "manifests", "Output format for 'exo gen k8s' (manifests, kustomize)")
+	genCmd.Flags().Bool("with-deps", false, "Add the configured DB and monitoring charts as dependencies in 'exo gen helm'")
+	genCmd.Flags().String("tool", "", "Tool for 'exo gen gitops' (argocd, flux), 'exo gen devloop' (tilt, skaffold) or 'exo gen release' (goreleaser, semantic-release, changesets, release-please)")
+	genCmd.Flags().String("cluster", "", "Local cluster for 'exo gen devloop' (kind, k3d)")
+	genCmd.Flags().Bool("deploy", false, "Add per-environment deploy jobs (terraform apply + rollout) to 'exo gen ci'")
+	genCmd.Flags().Bool("override", false, "Also write compose.override.yml running the app from source with hot reload in 'exo gen docker-compose'")
 	genCmd.Flags().StringP("output-dir", "o", "", "Write generated files into this directory instead of the current directory")
 }
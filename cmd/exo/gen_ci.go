@@ -1,44 +1,511 @@
 package exo
 
 import (
+	"bytes"
 	"fmt"
+	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/Harsh-BH/Exo/internal/config"
 )
 
-func generateCI(cwd string, data config.TemplateData, dryRun, force bool) error {
-	ci := data.CI
-	if ci == "" || ci == "none" {
-		ci = "github-actions" // default
+// ciTarget is a CI system exo can generate a pipeline for. GitHub Actions is
+// not listed: it gets one workflow per concern (see ciPath).
+type ciTarget struct {
+	Label string
+	Tmpl  string // under templates/ci/
+	Out   string // relative to the repo root
+}
+
+var ciTargets = map[string]ciTarget{
+	"gitlab-ci":           {"GitLab CI", "gitlab-ci.tmpl", ".gitlab-ci.yml"},
+	"jenkins":             {"Jenkins", "jenkinsfile.tmpl", "Jenkinsfile"},
+	"circleci":            {"CircleCI", "circleci.tmpl", filepath.Join(".circleci", "config.yml")},
+	"azure-pipelines":     {"Azure Pipelines", "azure-pipelines.tmpl", "azure-pipelines.yml"},
+	"bitbucket-pipelines": {"Bitbucket Pipelines", "bitbucket-pipelines.tmpl", "bitbucket-pipelines.yml"},
+	"woodpecker":          {"Woodpecker CI", "woodpecker.tmpl", ".woodpecker.yml"},
+}
+
+// ciToolchain holds the commands CI runs for one package manager. The
+// container-based systems run them verbatim in Image; GitHub Actions sets the
+// language up through its setup actions and runs the same commands.
+type ciToolchain struct {
+	Image    string
+	Version  string   // default entry of the version matrix
+	Setup    []string // what the setup actions do on GitHub; containers run it first
+	Install  []string
+	Lint     []string // the tools 'exo lint' runs
+	Build    []string
+	Test     []string
+	Coverage string // report Test writes, if any
+
+	// GitLab reads coverage from the job log: CoverageLog pulls the total out
+	// of Test's output, and Cobertura says Coverage is in that format.
+	CoverageLog string
+	Cobertura   bool
+
+	// GitLab only caches paths inside the checkout ($CI_PROJECT_DIR):
+	// CacheEnv points the package manager's cache at CachePaths, and CacheKey
+	// is the lockfile that invalidates it.
+	CacheEnv   map[string]string
+	CacheKey   string
+	CachePaths []string
+}
+
+var ciToolchains = map[string]ciToolchain{
+	"go": {
+		Image:    "golang:1.22",
+		Version:  "1.22",
+		Lint:     []string{"go vet ./..."},
+		Build:    []string{"go build -v ./..."},
+		Test:     []string{"go test -v -race -coverprofile=coverage.out ./...", "go tool cover -func=coverage.out"},
+		Coverage: "coverage.out",
+
+		CoverageLog: `/total:\s+\(statements\)\s+(\d+\.\d+)%/`,
+		CacheEnv:    map[string]string{"GOPATH": "$CI_PROJECT_DIR/.go", "GOCACHE": "$CI_PROJECT_DIR/.cache/go-build"},
+		CacheKey:    "go.sum",
+		CachePaths:  []string{".go/pkg/mod", ".cache/go-build"},
+	},
+	"npm": {
+		Image:    "node:20",
+		Version:  "20",
+		Install:  []string{"npm ci"},
+		Lint:     []string{"npx eslint ."},
+		Build:    []string{"npm run build --if-present"},
+		Test:     []string{"npm test -- --coverage --watchAll=false"},
+		Coverage: "coverage/lcov.info",
+
+		CoverageLog: `/All files[^|]*\|[^|]*\s+([\d\.]+)/`,
+		CacheEnv:    map[string]string{"npm_config_cache": "$CI_PROJECT_DIR/.npm"},
+		CacheKey:    "package-lock.json",
+		CachePaths:  []string{".npm"},
+	},
+	"yarn": {
+		Image:    "node:20",
+		Version:  "20",
+		Install:  []string{"yarn install --frozen-lockfile"},
+		Lint:     []string{"yarn eslint ."},
+		Build:    []string{"npm run build --if-present"},
+		Test:     []string{"yarn test --coverage --watchAll=false"},
+		Coverage: "coverage/lcov.info",
+
+		CoverageLog: `/All files[^|]*\|[^|]*\s+([\d\.]+)/`,
+		CacheEnv:    map[string]string{"YARN_CACHE_FOLDER": "$CI_PROJECT_DIR/.yarn-cache"},
+		CacheKey:    "yarn.lock",
+		CachePaths:  []string{".yarn-cache"},
+	},
+	"pnpm": {
+		Image:    "node:20",
+		Version:  "20",
+		Setup:    []string{"corepack enable"},
+		Install:  []string{"pnpm install --frozen-lockfile"},
+		Lint:     []string{"pnpm exec eslint ."},
+		Build:    []string{"pnpm run --if-present build"},
+		Test:     []string{"pnpm test -- --coverage --watchAll=false"},
+		Coverage: "coverage/lcov.info",
+
+		CoverageLog: `/All files[^|]*\|[^|]*\s+([\d\.]+)/`,
+		CacheEnv:    map[string]string{"npm_config_store_dir": "$CI_PROJECT_DIR/.pnpm-store"},
+		CacheKey:    "pnpm-lock.yaml",
+		CachePaths:  []string{".pnpm-store"},
+	},
+	"pip": {
+		Image:    "python:3.12-slim",
+		Version:  "3.12",
+		Install:  []string{"pip install -r requirements.txt pytest pytest-cov ruff"},
+		Lint:     []string{"ruff check ."},
+		Test:     []string{"pytest --cov=. --cov-report=xml --cov-report=term"},
+		Coverage: "coverage.xml",
+
+		CoverageLog: `/TOTAL.*\s+(\d+%)$/`,
+		Cobertura:   true,
+		CacheEnv:    map[string]string{"PIP_CACHE_DIR": "$CI_PROJECT_DIR/.cache/pip"},
+		CacheKey:    "requirements.txt",
+		CachePaths:  []string{".cache/pip"},
+	},
+	"poetry": {
+		Image:    "python:3.12-slim",
+		Version:  "3.12",
+		Setup:    []string{"pip install poetry"},
+		Install:  []string{"poetry install --no-interaction", "pip install ruff"},
+		Lint:     []string{"ruff check ."},
+		Test:     []string{"poetry run pytest --cov=. --cov-report=xml --cov-report=term"},
+		Coverage: "coverage.xml",
+
+		CoverageLog: `/TOTAL.*\s+(\d+%)$/`,
+		Cobertura:   true,
+		CacheEnv:    map[string]string{"PIP_CACHE_DIR": "$CI_PROJECT_DIR/.cache/pip", "POETRY_CACHE_DIR": "$CI_PROJECT_DIR/.cache/poetry"},
+		CacheKey:    "poetry.lock",
+		CachePaths:  []string{".cache/pip", ".cache/poetry"},
+	},
+	"maven": {
+		Image:   "maven:3.9-eclipse-temurin-21",
+		Version: "21",
+		Build:   []string{"mvn --no-transfer-progress package -DskipTests"},
+		Test:    []string{"mvn --no-transfer-progress verify"},
+
+		CacheEnv:   map[string]string{"MAVEN_OPTS": "-Dmaven.repo.local=$CI_PROJECT_DIR/.m2/repository"},
+		CacheKey:   "pom.xml",
+		CachePaths: []string{".m2/repository"},
+	},
+	"gradle": {
+		Image:   "eclipse-temurin:21",
+		Version: "21",
+		Build:   []string{"./gradlew build -x test"},
+		Test:    []string{"./gradlew test"},
+
+		CacheEnv:   map[string]string{"GRADLE_USER_HOME": "$CI_PROJECT_DIR/.gradle"},
+		CacheKey:   "gradle/wrapper/gradle-wrapper.properties",
+		CachePaths: []string{".gradle/caches", ".gradle/wrapper"},
+	},
+	"cargo": {
+		Image:   "rust:1",
+		Version: "stable",
+		Setup:   []string{"rustup component add clippy"},
+		Lint:    []string{"cargo clippy -- -D warnings"},
+		Build:   []string{"cargo build --verbose"},
+		Test:    []string{"cargo test --verbose"},
+
+		CacheEnv:   map[string]string{"CARGO_HOME": "$CI_PROJECT_DIR/.cargo"},
+		CacheKey:   "Cargo.lock",
+		CachePaths: []string{".cargo/registry", "target"},
+	},
+}
+
+// Prepare is everything a bare container runs before linting: Setup, then
+// Install.
+func (t ciToolchain) Prepare() []string {
+	return append(append([]string{}, t.Setup...), t.Install...)
+}
+
+// MatrixImage is Toolchain.Image at the version a matrix job sets in
+// $VERSION, or "" when the tag doesn't carry the version and every entry
+// would run in the same container.
+func (d ciData) MatrixImage() string {
+	img := pinToolchain(d.Toolchain, "${VERSION}").Image
+	if img == d.Toolchain.Image {
+		return ""
 	}
+	return img
+}
 
-	switch ci {
-	case "github-actions":
-		ciDir := filepath.Join(cwd, ".github", "workflows")
-		outName := data.Language + ".yml"
-		if data.Language == "" || data.Language == "unknown" {
-			outName = "ci.yml"
+// ciPackageManager picks the package manager for lang from the lockfiles in
+// cwd, so CI installs and caches with the same tool developers use.
+func ciPackageManager(cwd, lang string) string {
+	switch lang {
+	case "go":
+		return "go"
+	case "node":
+		switch {
+		case fileExists(filepath.Join(cwd, "pnpm-lock.yaml")):
+			return "pnpm"
+		case fileExists(filepath.Join(cwd, "yarn.lock")):
+			return "yarn"
 		}
-		tmpl := filepath.Join("templates", "ci", "github-actions.tmpl")
-		out := filepath.Join(ciDir, outName)
-		if err := renderFile(tmpl, out, data, dryRun, force); err != nil {
-			return fmt.Errorf("github-actions: %w", err)
+		return "npm"
+	case "python":
+		if fileExists(filepath.Join(cwd, "poetry.lock")) {
+			return "poetry"
 		}
-		if !dryRun {
-			fmt.Printf("  ✓  GitHub Actions → .github/workflows/%s\n", outName)
+		return "pip"
+	case "java":
+		if fileExists(filepath.Join(cwd, "build.gradle")) || fileExists(filepath.Join(cwd, "build.gradle.kts")) {
+			return "gradle"
 		}
-	case "gitlab-ci":
-		tmpl := filepath.Join("templates", "ci", "gitlab-ci.tmpl")
-		out := filepath.Join(cwd, ".gitlab-ci.yml")
-		if err := renderFile(tmpl, out, data, dryRun, force); err != nil {
-			return fmt.Errorf("gitlab-ci: %w", err)
+		return "maven"
+	case "rust":
+		return "cargo"
+	}
+	return ""
+}
+
+// toolchainFor returns the toolchain for pm, or echo placeholders for a
+// language exo has no defaults for.
+func toolchainFor(pm, lang string) ciToolchain {
+	if tc, ok := ciToolchains[pm]; ok {
+		return tc
+	}
+	return ciToolchain{
+		Image: "alpine:3.20",
+		Build: []string{fmt.Sprintf("echo \"Configure build steps for %s\"", lang)},
+		Test:  []string{fmt.Sprintf("echo \"Configure test steps for %s\"", lang)},
+	}
+}
+
+// pinToolchain moves tc to the language version the project pins (see
+// toolchainVersion), so the matrix and CI images build with what the project
+// declares rather than exo's default.
+func pinToolchain(tc ciToolchain, version string) ciToolchain {
+	if version == "" || tc.Version == "" {
+		return tc
+	}
+	if i := strings.LastIndex(tc.Image, ":"); i >= 0 {
+		tc.Image = tc.Image[:i+1] + strings.Replace(tc.Image[i+1:], tc.Version, version, 1)
+	}
+	tc.Version = version
+	return tc
+}
+
+// ciData is the rendering context for the CI templates. Deploys is filled by
+// --deploy with one job per environment, promoted in .exo.yaml order.
+type ciData struct {
+	config.TemplateData
+	PackageManager string // go | npm | yarn | pnpm | pip | poetry | maven | gradle | cargo
+	Toolchain      ciToolchain
+	Matrix         []string // language versions the test job runs on
+
+	// Optional jobs, switched on by what the project already has.
+	Docker    bool   // a Dockerfile to build, SBOM and scan
+	Chart     string // Helm chart to lint, relative to the repo
+	Terraform bool   // infra/<provider> to validate, and plan on pull requests
+	Release   string // release tool exo gen release configured, for GitLab's release jobs
+
+	TFDir      string // Terraform module plan and deploy jobs use, relative to the repo
+	Workspaces bool   // environments are Terraform workspaces rather than envs/<env>
+	Rollout    string // helm | kustomize | manifests | terraform
+	Deploys    []ciDeploy
+}
+
+// ciDeploy is one environment's deploy job.
+type ciDeploy struct {
+	envProfile
+	Job       string
+	Needs     string // job this deploy waits for
+	Namespace string
+}
+
+// newCIData composes the pipeline for data from what exists in cwd.
+func newCIData(cwd string, data config.TemplateData) ciData {
+	pm := ciPackageManager(cwd, data.Language)
+	d := ciData{
+		TemplateData:   data,
+		PackageManager: pm,
+		Toolchain:      pinToolchain(toolchainFor(pm, data.Language), toolchainVersion(cwd, data.Language)),
+		Docker:         data.Registry != "" || fileExists(filepath.Join(cwd, "Dockerfile")),
+		TFDir:          "infra/" + data.Provider,
+		Workspaces:     data.StateLayout == "workspaces",
+	}
+	d.Matrix = data.CIVersions
+	if len(d.Matrix) == 0 && d.Toolchain.Version != "" {
+		d.Matrix = []string{d.Toolchain.Version}
+	}
+	if chart := filepath.Join("charts", data.AppName); fileExists(filepath.Join(cwd, chart, "Chart.yaml")) {
+		d.Chart = filepath.ToSlash(chart)
+	}
+	if _, ok := stateBackends[data.Provider]; ok {
+		d.Terraform = fileExists(filepath.Join(cwd, d.TFDir, "main.tf"))
+	}
+	for _, tool := range []string{"goreleaser", "semantic-release"} {
+		if fileExists(filepath.Join(cwd, releaseTools[tool][0].out)) {
+			d.Release = tool
 		}
-		if !dryRun {
-			fmt.Printf("  ✓  GitLab CI → .gitlab-ci.yml\n")
+	}
+	return d
+}
+
+// newCIDeploys checks that data can be deployed from CI and lists the deploy
+// jobs. Kubernetes compute rolls out whatever 'exo gen helm' or 'exo gen k8s'
+// left in cwd; the other targets take the image as a Terraform variable.
+func newCIDeploys(cwd string, data config.TemplateData) (ciData, error) {
+	d := newCIData(cwd, data)
+	if _, ok := stateBackends[data.Provider]; !ok {
+		return d, fmt.Errorf("--deploy needs a cloud provider (aws, gcp, azure)")
+	}
+	if data.Registry == "" {
+		return d, fmt.Errorf("--deploy needs registry in .exo.yaml: deploy jobs roll out the image CI pushes there")
+	}
+	if data.StateBackend == "" {
+		return d, fmt.Errorf("--deploy needs terraform.backend in .exo.yaml: CI runners cannot keep local state")
+	}
+	d.Rollout = "terraform"
+	if data.Compute == "" || data.Compute == "kubernetes" {
+		src, err := detectGitOpsSource(cwd, data.AppName)
+		if err != nil {
+			return d, err
 		}
-	default:
-		return fmt.Errorf("unknown CI type %q (github-actions, gitlab-ci)", ci)
+		d.Rollout = src.Kind
+	}
+
+	// On GitHub the deploy workflow starts once CI has pushed the image.
+	needs := ""
+	if data.CI == "gitlab-ci" {
+		needs = "publish"
+	}
+	for _, env := range data.Environments {
+		job := "deploy-" + env
+		d.Deploys = append(d.Deploys, ciDeploy{
+			envProfile: newEnvProfile(data, env),
+			Job:        job,
+			Needs:      needs,
+			Namespace:  fmt.Sprintf("%s-%s", data.AppName, env),
+		})
+		needs = job
+	}
+	return d, nil
+}
+
+// ciRepository returns the owner/name path of the origin remote, which the
+// CI identity in ci.tf trusts, or a placeholder when there is none.
+func ciRepository(cwd, appName string) string {
+	url := gitRemoteURL(cwd)
+	if i := strings.Index(url, "://"); i >= 0 {
+		url = url[i+3:]
+	}
+	parts := strings.SplitN(strings.TrimSuffix(url, ".git"), "/", 2)
+	if len(parts) < 2 || parts[1] == "" {
+		return "your-org/" + appName
+	}
+	return parts[1]
+}
+
+// ciTypes lists the accepted values of ci in .exo.yaml and --ci.
+func ciTypes() []string {
+	types := []string{"github-actions"}
+	for name := range ciTargets {
+		types = append(types, name)
+	}
+	sort.Strings(types[1:])
+	return types
+}
+
+// ciPath returns where the pipeline part for concern (ci, release, deploy or
+// security) lives, relative to the repo root. GitHub Actions gets one
+// workflow per concern; the other systems keep everything in one file.
+func ciPath(ci, concern string) string {
+	if t, ok := ciTargets[ci]; ok {
+		return t.Out
+	}
+	return filepath.Join(".github", "workflows", concern+".yml")
+}
+
+// ciFiles lists the files 'exo gen ci' writes for d.
+func ciFiles(cwd string, d ciData) []genFile {
+	if t, ok := ciTargets[d.CI]; ok {
+		return []genFile{{filepath.Join("ci", t.Tmpl), filepath.Join(cwd, t.Out), d}}
+	}
+	files := []genFile{
+		{"ci/github-actions.tmpl", filepath.Join(cwd, ciPath(d.CI, "ci")), d},
+		{"ci/github-security.tmpl", filepath.Join(cwd, ciPath(d.CI, "security")), d},
+	}
+	if len(d.Deploys) > 0 {
+		files = append(files, genFile{"ci/github-deploy.tmpl", filepath.Join(cwd, ciPath(d.CI, "deploy")), d})
+	}
+	return files
+}
+
+// legacyCIWorkflows are the names older exo versions gave the GitHub Actions
+// pipeline: go.yml (init, add), <lang>.yml (gen) and <lang>-ci.yml (diff).
+func legacyCIWorkflows(lang string) []string {
+	names := []string{"go.yml"}
+	if lang != "" && lang != "go" {
+		names = append(names, lang+".yml")
+	}
+	if lang != "" {
+		names = append(names, lang+"-ci.yml")
+	}
+	return names
+}
+
+// migrateLegacyCI renames a pipeline exo wrote under a legacy name to ci.yml,
+// so regenerating does not leave two workflows running the same jobs, and
+// reports whether it did. Files exo did not write are left alone.
+func migrateLegacyCI(cwd, lang string, dryRun bool) (bool, error) {
+	dir := filepath.Join(cwd, ".github", "workflows")
+	target := filepath.Join(dir, "ci.yml")
+	for _, name := range legacyCIWorkflows(lang) {
+		old := filepath.Join(dir, name)
+		content, err := os.ReadFile(old)
+		if err != nil || !(bytes.HasPrefix(content, []byte("name: Build and Test\n")) || bytes.HasPrefix(content, []byte("name: CI\n"))) {
+			continue
+		}
+		if fileExists(target) {
+			fmt.Printf("  ⚠  .github/workflows/%s is an older exo pipeline; delete it, ci.yml replaces it\n", name)
+			continue
+		}
+		if dryRun {
+			fmt.Printf("  [dry-run] would rename .github/workflows/%s → ci.yml\n", name)
+			return true, nil
+		}
+		if err := os.Rename(old, target); err != nil {
+			return false, fmt.Errorf("renaming %s: %w", name, err)
+		}
+		fmt.Printf("  ↻  .github/workflows/%s → ci.yml\n", name)
+		return true, nil
+	}
+	return false, nil
+}
+
+// writeCI migrates legacy workflow names and renders d's pipeline files,
+// returning the paths written relative to cwd. init, add and gen all go
+// through here so they agree on where CI lives. A migrated ci.yml is always
+// regenerated: it is exo's own output, and keeping it would leave the old
+// pipeline running next to the jobs now split into other workflows.
+func writeCI(cwd string, d ciData, dryRun, force bool) ([]string, error) {
+	migrated := false
+	if _, ok := ciTargets[d.CI]; !ok {
+		if d.CI != "github-actions" {
+			return nil, fmt.Errorf("unknown CI type %q (%s)", d.CI, strings.Join(ciTypes(), ", "))
+		}
+		var err error
+		if migrated, err = migrateLegacyCI(cwd, d.Language, dryRun); err != nil {
+			return nil, err
+		}
+	}
+	files := ciFiles(cwd, d)
+	migratedPath := filepath.Join(cwd, ciPath(d.CI, "ci"))
+	for _, f := range files {
+		if err := renderFiles(cwd, []genFile{f}, dryRun, force || (migrated && f.out == migratedPath)); err != nil {
+			return nil, err
+		}
+	}
+	var written []string
+	for _, f := range files {
+		rel, _ := filepath.Rel(cwd, f.out)
+		written = append(written, filepath.ToSlash(rel))
+	}
+	return written, nil
+}
+
+// ciLabel names the CI system for progress output.
+func ciLabel(ci string) string {
+	if t, ok := ciTargets[ci]; ok {
+		return t.Label
+	}
+	if ci == "github-actions" {
+		return "GitHub Actions"
+	}
+	return ci
+}
+
+func generateCI(cwd string, data config.TemplateData, deploy, dryRun, force bool) error {
+	ci := data.CI
+	if ci == "" || ci == "none" {
+		ci = "github-actions" // default
+	}
+	data.CI = ci
+
+	d := newCIData(cwd, data)
+	if deploy {
+		if ci != "github-actions" && ci != "gitlab-ci" {
+			return fmt.Errorf("--deploy supports github-actions and gitlab-ci, not %s", ci)
+		}
+		var err error
+		if d, err = newCIDeploys(cwd, data); err != nil {
+			return err
+		}
+	}
+
+	written, err := writeCI(cwd, d, dryRun, force)
+	if err != nil {
+		return err
+	}
+	if !dryRun {
+		fmt.Printf("  ✓  %s → %s\n", ciLabel(ci), strings.Join(written, ", "))
+	}
+	if deploy && !dryRun {
+		fmt.Printf("  ℹ  deploy jobs sign in with the ci_variables output of infra/%s (ci.tf); apply it once per environment first\n", data.Provider)
 	}
 	return nil
 }
@@ -0,0 +1,268 @@
+package exo
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Harsh-BH/Exo/internal/config"
+	"github.com/Harsh-BH/Exo/internal/renderer"
+)
+
+// composeService is one entry under services:, rendered from its fragment.
+type composeService struct {
+	Name string
+	Body string
+}
+
+// composeData is the rendering context for compose files and the service
+// fragments they are assembled from. Fragments render with the same context
+// before the file itself does.
+type composeData struct {
+	config.TemplateData
+	File string
+	// Project is the compose project name. Standalone files get their own so
+	// running one doesn't report the others' services as orphans; they still
+	// meet on the named networks.
+	Project  string
+	Services []composeService
+	Networks []string
+	Volumes  []string
+	Secrets  []string // env variables passed to services as compose secrets
+	// Profiles puts services into the app, db and monitoring profiles. Only
+	// the full docker-compose.yml uses them; the standalone files start
+	// everything they define.
+	Profiles  bool
+	Monitored bool         // app joins the monitoring network
+	Database  *appDatabase // nil without a supported db
+	ConfigDir string       // directory holding prometheus.yml, relative to the compose file
+	// Healthcheck is the app's compose healthcheck test, empty when the
+	// runtime image can't probe itself.
+	Healthcheck string
+	// Internal leaves host ports unpublished; the dev container forwards
+	// them instead, so it can run next to docker-compose.yml.
+	Internal bool
+	// HostApp means the app runs on the host rather than in this file, so
+	// the metrics backend reaches it as "app" through the host gateway.
+	HostApp bool
+	Image   string // dev container workspace image
+	// GrafanaPort is Grafana's host port: 3000, or 3001 when the app
+	// publishes 3000.
+	GrafanaPort int
+}
+
+// composeDBEnv is the env contract from .env.example for each database, with
+// hosts pointing at the compose service. {app} is the app name.
+var composeDBEnv = map[string][]string{
+	"postgres": {
+		"POSTGRES_HOST: postgres",
+		`POSTGRES_PORT: "5432"`,
+		"POSTGRES_USER: ${POSTGRES_USER:-admin}",
+		"POSTGRES_PASSWORD: ${POSTGRES_PASSWORD:-secret}",
+		"POSTGRES_DB: ${POSTGRES_DB:-{app}}",
+		"DATABASE_URL: postgres://${POSTGRES_USER:-admin}:${POSTGRES_PASSWORD:-secret}@postgres:5432/${POSTGRES_DB:-{app}}?sslmode=disable",
+	},
+	"mysql": {
+		"MYSQL_HOST: mysql",
+		`MYSQL_PORT: "3306"`,
+		"MYSQL_USER: ${MYSQL_USER:-admin}",
+		"MYSQL_PASSWORD: ${MYSQL_PASSWORD:-secret}",
+		"MYSQL_DB: ${MYSQL_DB:-{app}}",
+		"DATABASE_URL: mysql://${MYSQL_USER:-admin}:${MYSQL_PASSWORD:-secret}@mysql:3306/${MYSQL_DB:-{app}}",
+	},
+	"mongo": {
+		"MONGO_HOST: mongo",
+		`MONGO_PORT: "27017"`,
+		"MONGO_USER: ${MONGO_USER:-admin}",
+		"MONGO_PASSWORD: ${MONGO_PASSWORD:-secret}",
+		"MONGO_DB: ${MONGO_DB:-{app}}",
+		"MONGO_URI: mongodb://${MONGO_USER:-admin}:${MONGO_PASSWORD:-secret}@mongo:27017/${MONGO_DB:-{app}}?authSource=admin",
+	},
+	"redis": {
+		"REDIS_HOST: redis",
+		`REDIS_PORT: "6379"`,
+		"REDIS_PASSWORD: ${REDIS_PASSWORD:-secret}",
+		"REDIS_URL: redis://:${REDIS_PASSWORD:-secret}@redis:6379/0",
+	},
+}
+
+// Env returns the app's environment entries, shared by the app and dev
+// container workspace services.
+func (d composeData) Env() []string {
+	env := []string{
+		"APP_ENV: development",
+		fmt.Sprintf(`APP_PORT: "%d"`, d.Port),
+		fmt.Sprintf(`PORT: "%d"`, d.Port),
+	}
+	for _, e := range composeDBEnv[d.DB] {
+		env = append(env, strings.ReplaceAll(e, "{app}", d.AppName))
+	}
+	if r := d.Monitoring.OTLPReceiver(); r != "" {
+		env = append(env,
+			"OTEL_SERVICE_NAME: "+d.AppName,
+			"OTEL_EXPORTER_OTLP_ENDPOINT: http://"+r+":4318",
+		)
+	}
+	return env
+}
+
+// composeVolumes are the monitoring services that keep their data in a named
+// volume. Loki and Tempo hold development data only and start empty.
+var composeVolumes = map[string]bool{"prometheus": true, "victoriametrics": true, "alertmanager": true, "grafana": true}
+
+// composeFragment returns the template, relative to templates/, for a service.
+func composeFragment(service string) string {
+	switch service {
+	case "app", "workspace":
+		return "compose/" + service + ".tmpl"
+	case "postgres", "mysql", "mongo", "redis":
+		return "db/" + service + ".tmpl"
+	}
+	return "monitoring/compose-" + service + ".tmpl"
+}
+
+// newComposeData returns the context for a compose file named file in the
+// compose project project, whose monitoring configs live in configDir.
+func newComposeData(data config.TemplateData, file, project, configDir string) composeData {
+	d := composeData{TemplateData: data, File: file, Project: project, ConfigDir: configDir}
+	d.GrafanaPort = 3000
+	if data.Port == d.GrafanaPort {
+		d.GrafanaPort = 3001
+	}
+	if db, ok := appDatabases[data.DB]; ok {
+		d.Database = &db
+	}
+	d.Monitored = data.Monitoring.Enabled()
+	return d
+}
+
+// withServices renders each service's fragment into d and collects the
+// networks and named volumes they use.
+func (d composeData) withServices(services ...string) (composeData, error) {
+	networks := map[string]bool{}
+	for _, name := range services {
+		body, err := renderer.RenderToString(filepath.Join("templates", composeFragment(name)), d)
+		if err != nil {
+			return d, fmt.Errorf("%s service: %w", name, err)
+		}
+		d.Services = append(d.Services, composeService{Name: name, Body: strings.TrimRight(body, "\n")})
+		switch {
+		case name == "app" || name == "workspace":
+			networks["backend"] = true
+			networks["monitoring"] = networks["monitoring"] || d.Monitored
+		case strings.HasPrefix(composeFragment(name), "db/"):
+			networks["backend"] = true
+			d.Volumes = append(d.Volumes, name+"_data")
+		default:
+			networks["monitoring"] = true
+			if composeVolumes[name] {
+				d.Volumes = append(d.Volumes, name+"_data")
+			}
+			if name == "alertmanager" {
+				d.Secrets = d.Alerts.Secrets()
+			}
+		}
+	}
+	for _, n := range []string{"backend", "monitoring"} {
+		if networks[n] {
+			d.Networks = append(d.Networks, n)
+		}
+	}
+	return d, nil
+}
+
+// dbComposeFile returns docker-compose.<db>.yml running only the database.
+func dbComposeFile(cwd string, data config.TemplateData) (genFile, error) {
+	if _, ok := appDatabases[data.DB]; !ok {
+		return genFile{}, fmt.Errorf("unknown database %q (postgres, mysql, mongo, redis)", data.DB)
+	}
+	file := fmt.Sprintf("docker-compose.%s.yml", data.DB)
+	d, err := newComposeData(data, file, data.AppName+"-"+data.DB, ".").withServices(data.DB)
+	if err != nil {
+		return genFile{}, err
+	}
+	return genFile{"compose/compose.tmpl", filepath.Join(cwd, file), d}, nil
+}
+
+// monitoringFiles returns monitoring/ with the stack's configs and a compose
+// file running it on its own, next to an app started on the host.
+func monitoringFiles(cwd string, data config.TemplateData) ([]genFile, error) {
+	monDir := filepath.Join(cwd, "monitoring")
+	d := newComposeData(data, "docker-compose.monitoring.yml", data.AppName+"-monitoring", ".")
+	d.HostApp = true
+	d, err := d.withServices(monitoringServices(data.Monitoring)...)
+	if err != nil {
+		return nil, err
+	}
+	files, err := monitoringConfigs(monDir, data)
+	if err != nil {
+		return nil, err
+	}
+	return append(files, genFile{"compose/compose.tmpl", filepath.Join(monDir, "docker-compose.monitoring.yml"), d}), nil
+}
+
+// composeFiles returns docker-compose.yml running the app, its database and
+// the monitoring stack under profiles, plus what those services mount. With
+// override, compose.override.yml runs the Dockerfile's dev stage against the
+// mounted source for hot reload.
+func composeFiles(cwd string, data config.TemplateData, override bool) ([]genFile, error) {
+	dd, err := newDockerData(cwd, data)
+	if err != nil {
+		return nil, err
+	}
+	d := newComposeData(data, "docker-compose.yml", data.AppName, "./monitoring")
+	d.Profiles = true
+	if dd.Healthcheck != "" {
+		d.Healthcheck = `["CMD", ` + strings.TrimPrefix(dd.Healthcheck, "[")
+	}
+
+	services := []string{"app"}
+	if d.Database != nil {
+		services = append(services, data.DB)
+	}
+	services = append(services, monitoringServices(data.Monitoring)...)
+	if d, err = d.withServices(services...); err != nil {
+		return nil, err
+	}
+
+	configs, err := monitoringConfigs(filepath.Join(cwd, "monitoring"), data)
+	if err != nil {
+		return nil, err
+	}
+	files := append([]genFile{{"compose/compose.tmpl", filepath.Join(cwd, "docker-compose.yml"), d}}, configs...)
+	if override {
+		files = append(files, genFile{"compose/override.tmpl", filepath.Join(cwd, "compose.override.yml"), d})
+	}
+	return files, nil
+}
+
+func generateDockerCompose(cwd string, data config.TemplateData, override, dryRun, force bool) error {
+	switch data.Language {
+	case "go", "node", "python":
+	default:
+		if override {
+			fmt.Printf("  ℹ  compose.override.yml supports go, node and python; skipping it for %s\n", data.Language)
+			override = false
+		}
+	}
+	files, err := composeFiles(cwd, data, override)
+	if err != nil {
+		return fmt.Errorf("docker-compose: %w", err)
+	}
+	if err := renderFiles(cwd, files, dryRun, force); err != nil {
+		return fmt.Errorf("docker-compose: %w", err)
+	}
+	if !dryRun {
+		var names []string
+		for _, s := range files[0].data.(composeData).Services {
+			names = append(names, s.Name)
+		}
+		out := make([]string, len(files))
+		for i, f := range files {
+			rel, _ := filepath.Rel(cwd, f.out)
+			out[i] = filepath.ToSlash(rel)
+		}
+		fmt.Printf("  ✓  docker-compose (%s) → %s\n", strings.Join(names, ", "), strings.Join(out, ", "))
+	}
+	return nil
+}
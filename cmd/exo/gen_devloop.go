@@ -0,0 +1,165 @@
+package exo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Harsh-BH/Exo/internal/config"
+)
+
+// devloopRegistryPort is where the local registry listens on the host.
+const devloopRegistryPort = 5001
+
+// devloopData is the rendering context for the local cluster and the Tilt or
+// Skaffold config that builds the Dockerfile's dev stage into it.
+type devloopData struct {
+	config.TemplateData
+	Tool         string // tilt | skaffold
+	Cluster      string // kind | k3d
+	Context      string // kube context the cluster tool creates
+	Registry     string // registry container name
+	RegistryPort int
+	Source       gitopsSource
+	// Namespace is created by cluster.sh when the kustomize overlay deploys
+	// into its own namespace.
+	Namespace string
+	// Resources are devloop/kustomization.yaml's resources, relative to devloop/.
+	Resources []string
+	Workdir   string // WORKDIR of the dev stage, where source syncs to
+	Main      string
+	// SyncPatterns are the files Skaffold syncs instead of rebuilding.
+	SyncPatterns []string
+	// ImageKey is ImageRepository in the form Skaffold uses in template
+	// variables such as IMAGE_REPO_<key>.
+	ImageKey string
+}
+
+// devloopSync lists what the dev servers reload without a rebuild. Dependency
+// manifests are left out so changing them rebuilds the image.
+var devloopSync = map[string][]string{
+	"node":   {"**/*.js", "**/*.mjs", "**/*.cjs", "**/*.jsx", "**/*.ts", "**/*.tsx", "**/*.css", "public/**"},
+	"python": {"**/*.py", "**/*.html", "**/*.css", "**/*.js"},
+}
+
+var skaffoldKeyRe = regexp.MustCompile(`[^A-Za-z0-9]`)
+
+// newDevloopData picks the deploy source from previously generated output: the
+// Helm chart, else the dev kustomize overlay, else the plain k8s/ manifests.
+func newDevloopData(cwd string, data config.TemplateData, tool, cluster string) (devloopData, error) {
+	src, err := detectGitOpsSource(cwd, data.AppName)
+	if err != nil {
+		return devloopData{}, err
+	}
+	d := devloopData{
+		TemplateData: data,
+		Tool:         tool,
+		Cluster:      cluster,
+		Context:      cluster + "-" + data.AppName,
+		Registry:     data.AppName + "-registry",
+		RegistryPort: devloopRegistryPort,
+		Source:       src,
+		Workdir:      "/app",
+		SyncPatterns: devloopSync[data.Language],
+		ImageKey:     skaffoldKeyRe.ReplaceAllString(data.ImageRepository(), "_"),
+	}
+	// k3d prefixes the containers it creates.
+	if cluster == "k3d" {
+		d.Registry = "k3d-" + d.Registry
+	}
+	if data.Language == "go" {
+		d.Workdir = "/src"
+	}
+	d.Main, _ = stackEntry(cwd, data)
+
+	switch src.Kind {
+	case "kustomize":
+		env := data.Environments[0]
+		for _, e := range data.Environments {
+			if e == "dev" {
+				env = e
+			}
+		}
+		d.Source.Path = strings.ReplaceAll(src.Path, "{env}", env)
+		d.Namespace = data.AppName + "-" + env
+		d.Resources = []string{"../" + d.Source.Path}
+	case "manifests":
+		for _, f := range k8sManifests {
+			if fileExists(filepath.Join(cwd, "k8s", f)) {
+				d.Resources = append(d.Resources, "../k8s/"+f)
+			}
+		}
+	}
+	return d, nil
+}
+
+func generateDevloop(cwd string, data config.TemplateData, tool, cluster string, dryRun, force bool) error {
+	if tool == "" {
+		tool = "tilt"
+	}
+	if tool != "tilt" && tool != "skaffold" {
+		return fmt.Errorf("unknown dev loop tool %q (tilt, skaffold)", tool)
+	}
+	if cluster == "" {
+		cluster = "kind"
+	}
+	if cluster != "kind" && cluster != "k3d" {
+		return fmt.Errorf("unknown local cluster %q (kind, k3d)", cluster)
+	}
+	switch data.Language {
+	case "go", "node", "python":
+	default:
+		return fmt.Errorf("the dev loop live-syncs go, node and python; %s is not supported", data.Language)
+	}
+
+	d, err := newDevloopData(cwd, data, tool, cluster)
+	if err != nil {
+		return err
+	}
+
+	devDir := filepath.Join(cwd, "devloop")
+	files := []genFile{
+		{filepath.Join("devloop", cluster+".yaml.tmpl"), filepath.Join(devDir, cluster+".yaml"), d},
+		{"devloop/cluster.sh.tmpl", filepath.Join(devDir, "cluster.sh"), d},
+	}
+	if d.Source.Kind == "helm" {
+		files = append(files, genFile{"devloop/values-dev.yaml.tmpl", filepath.Join(devDir, "values-dev.yaml"), d})
+	} else {
+		files = append(files, genFile{"devloop/kustomization.yaml.tmpl", filepath.Join(devDir, "kustomization.yaml"), d})
+	}
+	if tool == "tilt" {
+		files = append(files, genFile{"devloop/Tiltfile.tmpl", filepath.Join(cwd, "Tiltfile"), d})
+	} else {
+		files = append(files, genFile{"devloop/skaffold.yaml.tmpl", filepath.Join(cwd, "skaffold.yaml"), d})
+	}
+	if err := renderFiles(cwd, files, dryRun, force); err != nil {
+		return fmt.Errorf("devloop: %w", err)
+	}
+	if dryRun {
+		return nil
+	}
+	if err := os.Chmod(filepath.Join(devDir, "cluster.sh"), 0755); err != nil {
+		return fmt.Errorf("devloop: %w", err)
+	}
+
+	var written []string
+	for _, f := range files {
+		rel, _ := filepath.Rel(cwd, f.out)
+		written = append(written, filepath.ToSlash(rel))
+	}
+	fmt.Printf("  ✓  Dev loop (%s on %s, %s) → %s\n", tool, cluster, d.Source.Kind, strings.Join(written, ", "))
+	if !fileExists(filepath.Join(cwd, "Dockerfile")) {
+		fmt.Println("  ⚠  no Dockerfile yet; run 'exo gen docker' so the dev stage exists")
+	}
+	if chart, err := os.ReadFile(filepath.Join(cwd, d.Source.Path, "Chart.yaml")); err == nil && strings.Contains(string(chart), "dependencies:") {
+		fmt.Printf("  ℹ  run 'helm dependency update %s' before the first deploy\n", d.Source.Path)
+	}
+	start := "tilt up"
+	if tool == "skaffold" {
+		start = "skaffold dev --port-forward"
+	}
+	fmt.Printf("  ℹ  start with ./devloop/cluster.sh up && %s\n", start)
+	return nil
+}
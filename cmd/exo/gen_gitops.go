@@ -0,0 +1,135 @@
+package exo
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/Harsh-BH/Exo/internal/config"
+)
+
+// gitopsSource describes the deployable output a GitOps controller syncs.
+type gitopsSource struct {
+	Kind    string // helm | kustomize | manifests
+	RepoURL string
+	Branch  string
+	// Path is the repo-relative directory for the source. For kustomize it
+	// contains an {env} placeholder resolved per environment.
+	Path string
+}
+
+// gitopsEnv is the rendering context for one environment's deploy object.
+type gitopsEnv struct {
+	envProfile
+	Source    gitopsSource
+	Path      string
+	Namespace string
+	Automated bool
+}
+
+// detectGitOpsSource inspects cwd for previously generated Helm or k8s
+// output and reads the git remote the controller should pull from.
+func detectGitOpsSource(cwd, appName string) (gitopsSource, error) {
+	src := gitopsSource{
+		RepoURL: gitRemoteURL(cwd),
+		Branch:  gitBranch(cwd),
+	}
+	chartPath := filepath.Join("charts", appName)
+	switch {
+	case fileExists(filepath.Join(cwd, chartPath, "Chart.yaml")):
+		src.Kind, src.Path = "helm", filepath.ToSlash(chartPath)
+	case fileExists(filepath.Join(cwd, "k8s", "overlays")):
+		src.Kind, src.Path = "kustomize", "k8s/overlays/{env}"
+	case fileExists(filepath.Join(cwd, "k8s")):
+		src.Kind, src.Path = "manifests", "k8s"
+	default:
+		return src, fmt.Errorf("no charts/%s or k8s/ output found; run 'exo gen helm' or 'exo gen k8s' first", appName)
+	}
+	return src, nil
+}
+
+// gitRemoteURL returns the origin remote as an HTTPS URL. scp-style SSH
+// remotes (git@host:org/repo.git) are rewritten because Flux cannot parse them.
+func gitRemoteURL(cwd string) string {
+	out, err := exec.Command("git", "-C", cwd, "remote", "get-url", "origin").Output()
+	url := strings.TrimSpace(string(out))
+	if err != nil || url == "" {
+		return ""
+	}
+	if strings.HasPrefix(url, "git@") {
+		url = "https://" + strings.Replace(strings.TrimPrefix(url, "git@"), ":", "/", 1)
+	}
+	return url
+}
+
+// gitBranch returns the checked-out branch, defaulting to main.
+func gitBranch(cwd string) string {
+	out, err := exec.Command("git", "-C", cwd, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	branch := strings.TrimSpace(string(out))
+	if err != nil || branch == "" || branch == "HEAD" {
+		return "main"
+	}
+	return branch
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func generateGitOps(cwd string, data config.TemplateData, tool string, dryRun, force bool) error {
+	if tool == "" {
+		tool = "argocd"
+	}
+	if tool != "argocd" && tool != "flux" {
+		return fmt.Errorf("unknown gitops tool %q (argocd, flux)", tool)
+	}
+
+	src, err := detectGitOpsSource(cwd, data.AppName)
+	if err != nil {
+		return err
+	}
+	if src.RepoURL == "" {
+		src.RepoURL = fmt.Sprintf("https://github.com/your-org/%s.git", data.AppName)
+		fmt.Printf("  ℹ  no git remote 'origin' found — using placeholder %s\n", src.RepoURL)
+	}
+
+	outDir := filepath.Join(cwd, "gitops", tool)
+
+	var stop func(error)
+	if !dryRun {
+		stop = startSpinner(fmt.Sprintf("Generating %s deploy objects (%s) → gitops/%s/", tool, src.Kind, tool))
+	}
+
+	var files []genFile
+	if tool == "flux" {
+		files = append(files, genFile{filepath.Join("gitops", "flux", "gitrepository.yaml.tmpl"), filepath.Join(outDir, "source.yaml"), gitopsEnv{envProfile: envProfile{TemplateData: data}, Source: src}})
+	}
+	for _, env := range data.Environments {
+		e := gitopsEnv{
+			envProfile: newEnvProfile(data, env),
+			Source:     src,
+			Path:       strings.ReplaceAll(src.Path, "{env}", env),
+			Namespace:  fmt.Sprintf("%s-%s", data.AppName, env),
+			// Production waits for a manual sync; everything else self-heals.
+			Automated: env != "prod" && env != "production",
+		}
+		tmpl := filepath.Join("gitops", "argocd", "application.yaml.tmpl")
+		if tool == "flux" {
+			tmpl = filepath.Join("gitops", "flux", "kustomization.yaml.tmpl")
+			if src.Kind == "helm" {
+				tmpl = filepath.Join("gitops", "flux", "helmrelease.yaml.tmpl")
+			}
+		}
+		files = append(files, genFile{tmpl, filepath.Join(outDir, env+".yaml"), e})
+	}
+
+	genErr := renderFiles(cwd, files, dryRun, force)
+
+	if !dryRun {
+		stop(genErr)
+	}
+	return genErr
+}
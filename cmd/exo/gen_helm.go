@@ -1,22 +1,187 @@
 package exo
 
 import (
+	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/Harsh-BH/Exo/internal/config"
+	"github.com/Harsh-BH/Exo/internal/renderer"
+	"gopkg.in/yaml.v3"
 )
 
-func generateHelm(cwd string, data config.TemplateData, dryRun, force bool) error {
+// helmChartTemplates lists the files under templates/helm/templates/ that make
+// up the chart, relative to the chart's templates/ directory.
+var helmChartTemplates = []string{
+	"_helpers.tpl",
+	"deployment.yaml",
+	"service.yaml",
+	"ingress.yaml",
+	"hpa.yaml",
+	"serviceaccount.yaml",
+	"configmap.yaml",
+	"secret.yaml",
+	"NOTES.txt",
+	filepath.Join("tests", "test-connection.yaml"),
+}
+
+// helmDependency is one entry in Chart.yaml dependencies. Name doubles as the
+// values.yaml key holding the subchart's settings.
+type helmDependency struct {
+	Name       string
+	Version    string
+	Repository string
+}
+
+// appDatabase describes a supported database's env contract (the same
+// variables .env.example declares) and its in-cluster bitnami subchart.
+type appDatabase struct {
+	helmDependency
+	EnvPrefix string // POSTGRES | MYSQL | MONGO | REDIS
+	Port      int
+	Service   string // in-cluster service name of the primary
+	SecretKey string // key in the subchart's secret holding the app password
+	URLVar    string
+	URL       string // uses $(VAR) expansion of the variables above
+}
+
+// helmChart is the rendering context for the chart. Database and
+// Dependencies are only set when generating an umbrella chart.
+type helmChart struct {
+	config.TemplateData
+	Dependencies []helmDependency
+	Database     *appDatabase
+	Monitored    bool // the monitoring stack runs as subcharts
+	// AlertmanagerConfig is the stack's Alertmanager routing, indented to
+	// sit under its config: key in values.yaml.
+	AlertmanagerConfig string
+	// RolloutController is argo-rollouts or flagger when Strategy is canary
+	// or blue-green, and empty for plain rolling updates.
+	RolloutController string
+}
+
+const bitnamiRepo = "oci://registry-1.docker.io/bitnamicharts"
+
+// appDatabases maps .exo.yaml db values to their env contract and bitnami
+// subchart. Service is the suffix appended to the app name to form the
+// in-cluster host.
+var appDatabases = map[string]appDatabase{
+	"postgres": {
+		helmDependency: helmDependency{"postgresql", "~16.0", bitnamiRepo},
+		EnvPrefix:      "POSTGRES",
+		Port:           5432,
+		Service:        "-postgresql",
+		SecretKey:      "password",
+		URLVar:         "DATABASE_URL",
+		URL:            "postgres://$(POSTGRES_USER):$(POSTGRES_PASSWORD)@$(POSTGRES_HOST):$(POSTGRES_PORT)/$(POSTGRES_DB)?sslmode=disable",
+	},
+	"mysql": {
+		helmDependency: helmDependency{"mysql", "~12.0", bitnamiRepo},
+		EnvPrefix:      "MYSQL",
+		Port:           3306,
+		Service:        "-mysql",
+		SecretKey:      "mysql-password",
+		URLVar:         "DATABASE_URL",
+		URL:            "mysql://$(MYSQL_USER):$(MYSQL_PASSWORD)@$(MYSQL_HOST):$(MYSQL_PORT)/$(MYSQL_DB)",
+	},
+	"mongo": {
+		helmDependency: helmDependency{"mongodb", "~16.0", bitnamiRepo},
+		EnvPrefix:      "MONGO",
+		Port:           27017,
+		Service:        "-mongodb",
+		SecretKey:      "mongodb-passwords",
+		URLVar:         "MONGO_URI",
+		URL:            "mongodb://$(MONGO_USER):$(MONGO_PASSWORD)@$(MONGO_HOST):$(MONGO_PORT)/$(MONGO_DB)",
+	},
+	"redis": {
+		helmDependency: helmDependency{"redis", "~20.0", bitnamiRepo},
+		EnvPrefix:      "REDIS",
+		Port:           6379,
+		Service:        "-redis-master",
+		SecretKey:      "redis-password",
+		URLVar:         "REDIS_URL",
+		URL:            "redis://:$(REDIS_PASSWORD)@$(REDIS_HOST):$(REDIS_PORT)/0",
+	},
+}
+
+// newHelmChart builds the chart context. With withDeps, the configured DB and
+// monitoring stack become subchart dependencies so the chart runs in-cluster
+// what docker-compose.yml runs locally.
+func newHelmChart(data config.TemplateData, withDeps bool) helmChart {
+	chart := helmChart{TemplateData: data}
+	if !withDeps {
+		return chart
+	}
+	if db, ok := appDatabases[data.DB]; ok {
+		db.Service = data.AppName + db.Service
+		chart.Database = &db
+		chart.Dependencies = append(chart.Dependencies, db.helmDependency)
+	}
+	if data.Monitoring.Enabled() {
+		chart.Monitored = true
+		for _, c := range data.Monitoring.Components() {
+			chart.Dependencies = append(chart.Dependencies, monitoringHelmDependencies[c]...)
+		}
+	}
+	return chart
+}
+
+// OTLPEndpoint returns the in-cluster OTLP/HTTP endpoint the app sends
+// telemetry to, or "" without an OTLP receiver among the subcharts.
+func (c helmChart) OTLPEndpoint() string {
+	if !c.Monitored {
+		return ""
+	}
+	switch c.Monitoring.OTLPReceiver() {
+	case "otel-collector":
+		return "http://" + c.AppName + "-otel-collector:4318"
+	case "tempo":
+		return "http://" + c.AppName + "-tempo:4318"
+	case "jaeger":
+		return "http://" + c.AppName + "-jaeger-collector:4318"
+	}
+	return ""
+}
+
+func generateHelm(cwd string, data config.TemplateData, withDeps, dryRun, force bool) error {
 	chartsDir := filepath.Join(cwd, "charts", data.AppName)
 	tmplsDir := filepath.Join(chartsDir, "templates")
+	chart := newHelmChart(data, withDeps)
+	extra, err := strategyManifests(data)
+	if err != nil {
+		return err
+	}
+	if chart.Monitored {
+		if chart.AlertmanagerConfig, err = helmAlertmanagerConfig(data); err != nil {
+			return fmt.Errorf("helm: %w", err)
+		}
+	}
+	if len(extra) > 0 {
+		chart.RolloutController = "argo-rollouts"
+		if data.Rollouts == "flagger" {
+			chart.RolloutController = "flagger"
+		}
+	}
 
-	files := []struct{ tmpl, out string }{
-		{"helm/Chart.yaml.tmpl", filepath.Join(chartsDir, "Chart.yaml")},
-		{"helm/values.yaml.tmpl", filepath.Join(chartsDir, "values.yaml")},
-		{"helm/templates/deployment.yaml.tmpl", filepath.Join(tmplsDir, "deployment.yaml")},
-		{"helm/templates/service.yaml.tmpl", filepath.Join(tmplsDir, "service.yaml")},
-		{"helm/templates/ingress.yaml.tmpl", filepath.Join(tmplsDir, "ingress.yaml")},
+	files := []genFile{
+		{"helm/Chart.yaml.tmpl", filepath.Join(chartsDir, "Chart.yaml"), chart},
+		{"helm/values.yaml.tmpl", filepath.Join(chartsDir, "values.yaml"), chart},
+	}
+	templates := helmChartTemplates
+	if chart.Monitored {
+		templates = append(templates[:len(templates):len(templates)],
+			"servicemonitor.yaml", "prometheusrule.yaml", "alertmanager-secret.yaml", "grafana-dashboard.yaml")
+		files = append(files,
+			genFile{"grafana/dashboard.json.tmpl", filepath.Join(chartsDir, "dashboards", data.AppName+".json"), data},
+			genFile{"alerts/alerts.yml.tmpl", filepath.Join(chartsDir, "rules", "alerts.yml"), data},
+		)
+	}
+	templates = append(templates[:len(templates):len(templates)], extra...)
+	templates = append(templates, tlsManifests(data)...)
+	for _, f := range templates {
+		files = append(files, genFile{filepath.Join("helm", "templates", f+".tmpl"), filepath.Join(tmplsDir, f), chart})
 	}
 
 	var stop func(error)
@@ -24,17 +189,207 @@ func generateHelm(cwd string, data config.TemplateData, dryRun, force bool) erro
 		stop = startSpinner(fmt.Sprintf("Generating Helm chart → charts/%s/", data.AppName))
 	}
 
-	var genErr error
-	for _, f := range files {
-		tmpl := filepath.Join("templates", f.tmpl)
-		if err := renderFile(tmpl, f.out, data, dryRun, force); err != nil {
-			genErr = fmt.Errorf("helm: %w", err)
-			break
-		}
+	genErr := renderFiles(cwd, files, dryRun, force)
+	if genErr == nil {
+		genErr = generateHelmSchema(chartsDir, chart, dryRun, force)
+	}
+	if genErr != nil {
+		genErr = fmt.Errorf("helm: %w", genErr)
 	}
 
 	if !dryRun {
 		stop(genErr)
+		if genErr == nil && len(chart.Dependencies) > 0 {
+			fmt.Printf("  ℹ  run 'helm dependency update charts/%s' to fetch subcharts\n", data.AppName)
+		}
 	}
 	return genErr
 }
+
+// helmAlertmanagerConfig renders the Alertmanager routing for the metrics
+// stack's Alertmanager, which mounts the <app>-alertmanager Secret under its
+// operator's secrets directory.
+func helmAlertmanagerConfig(data config.TemplateData) (string, error) {
+	if err := data.Alerts.Validate(); err != nil {
+		return "", fmt.Errorf("alerts: %w", err)
+	}
+	dir := "/etc/alertmanager/secrets/" + data.AppName + "-alertmanager"
+	if data.Monitoring.Metrics == "victoriametrics" {
+		dir = "/etc/vm/secrets/" + data.AppName + "-alertmanager"
+	}
+	out, err := renderer.RenderToString(filepath.Join("templates", "monitoring", "alertmanager.tmpl"), alertmanagerData{data, dir})
+	if err != nil {
+		return "", fmt.Errorf("alertmanager config: %w", err)
+	}
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = "      " + l
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// generateHelmSchema renders values.yaml in memory and writes a matching
+// values.schema.json so `helm install` rejects mistyped overrides.
+func generateHelmSchema(chartsDir string, chart helmChart, dryRun, force bool) error {
+	values, err := renderer.RenderToString(filepath.Join("templates", "helm", "values.yaml.tmpl"), chart)
+	if err != nil {
+		return fmt.Errorf("values.schema.json: %w", err)
+	}
+	open := make([]string, 0, len(chart.Dependencies))
+	for _, dep := range chart.Dependencies {
+		open = append(open, dep.Name)
+	}
+	schema, err := helmValuesSchema([]byte(values), open...)
+	if err != nil {
+		return fmt.Errorf("values.schema.json: %w", err)
+	}
+	return writeFile(filepath.Join(chartsDir, "values.schema.json"), schema, dryRun, force)
+}
+
+// openValues are the values.yaml sections users extend with keys of their
+// own: extra env vars, probe handlers, resource names and receiver secrets.
+var openValues = []string{
+	"env",
+	"livenessProbe",
+	"readinessProbe",
+	"resources.requests",
+	"resources.limits",
+	"alerting.secrets",
+}
+
+// helmValuesSchema infers a JSON Schema (draft-07) from a values.yaml document.
+// Objects are closed so a misspelled key fails, except openValues and the
+// sections named in open (the subcharts, which validate their own values).
+func helmValuesSchema(values []byte, open ...string) ([]byte, error) {
+	var root interface{}
+	if err := yaml.Unmarshal(values, &root); err != nil {
+		return nil, fmt.Errorf("parsing values: %w", err)
+	}
+	s := valuesSchema{open: map[string]bool{}}
+	for _, p := range append(open, openValues...) {
+		s.open[p] = true
+	}
+	schema := s.node("", root, false)
+	if props, ok := schema["properties"].(map[string]interface{}); ok {
+		// Helm hands every chart a global section, parent or not.
+		props["global"] = map[string]interface{}{"type": "object"}
+	}
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	out, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// valuesSchema infers schema nodes, tracking which dotted paths stay open.
+type valuesSchema struct {
+	open map[string]bool
+}
+
+// node returns the schema node describing v at path. Empty maps and lists
+// accept any members so users can fill them in (e.g. podAnnotations,
+// secretEnv); objects at or below an open path accept extra keys.
+func (s valuesSchema) node(path string, v interface{}, open bool) map[string]interface{} {
+	open = open || s.open[path]
+	switch val := v.(type) {
+	case map[string]interface{}:
+		props := map[string]interface{}{}
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := k
+			if path != "" {
+				child = path + "." + k
+			}
+			props[k] = s.node(child, val[k], open)
+		}
+		node := map[string]interface{}{"type": "object"}
+		if len(props) > 0 {
+			node["properties"] = props
+			if !open {
+				node["additionalProperties"] = false
+			}
+		}
+		return node
+	case []interface{}:
+		// List entries are records users add to (rollout steps take setWeight,
+		// pause, analysis, …), so their objects stay open; the keys the
+		// defaults use are typed from every entry, not just the first.
+		node := map[string]interface{}{"type": "array"}
+		for i, item := range val {
+			if i == 0 {
+				node["items"] = s.node(path, item, true)
+				continue
+			}
+			node["items"] = mergeSchema(node["items"].(map[string]interface{}), s.node(path, item, true))
+		}
+		return node
+	}
+	if isQuantity(path) {
+		// Kubernetes quantities: 500m and 512Mi as much as 1 or 0.5.
+		return map[string]interface{}{"type": []string{"string", "integer", "number"}}
+	}
+	switch v.(type) {
+	case string:
+		return map[string]interface{}{"type": "string"}
+	case int, int64:
+		return map[string]interface{}{"type": "integer"}
+	case float64:
+		return map[string]interface{}{"type": "number"}
+	case bool:
+		return map[string]interface{}{"type": "boolean"}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// mergeSchema combines the nodes of two list entries: objects take the union
+// of their properties, and entries of different types accept anything.
+func mergeSchema(a, b map[string]interface{}) map[string]interface{} {
+	if fmt.Sprint(a["type"]) != fmt.Sprint(b["type"]) {
+		return map[string]interface{}{}
+	}
+	merged := map[string]interface{}{}
+	for k, v := range a {
+		merged[k] = v
+	}
+	if ai, ok := a["items"].(map[string]interface{}); ok {
+		if bi, ok := b["items"].(map[string]interface{}); ok {
+			merged["items"] = mergeSchema(ai, bi)
+		}
+	} else if bi, ok := b["items"]; ok {
+		merged["items"] = bi
+	}
+	if bp, ok := b["properties"].(map[string]interface{}); ok {
+		props := map[string]interface{}{}
+		if ap, ok := a["properties"].(map[string]interface{}); ok {
+			for k, v := range ap {
+				props[k] = v
+			}
+		}
+		for k, v := range bp {
+			if prev, ok := props[k].(map[string]interface{}); ok {
+				v = mergeSchema(prev, v.(map[string]interface{}))
+			}
+			props[k] = v
+		}
+		merged["properties"] = props
+	}
+	return merged
+}
+
+// isQuantity reports whether path is a resource request or limit.
+func isQuantity(path string) bool {
+	i := strings.LastIndex(path, ".")
+	if i < 0 {
+		return false
+	}
+	parent := path[:i]
+	return strings.HasSuffix(parent, "resources.requests") || strings.HasSuffix(parent, "resources.limits")
+}
@@ -3,10 +3,175 @@ package exo
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/Harsh-BH/Exo/internal/config"
 )
 
+// stateBackends maps each provider to the remote state backend exo can
+// bootstrap for it.
+var stateBackends = map[string]string{"aws": "s3", "gcp": "gcs", "azure": "azurerm"}
+
+// infraDefaults holds the per-provider Terraform inputs used when .exo.yaml
+// leaves them unset.
+var infraDefaults = map[string]config.TemplateData{
+	"aws":   {Region: "us-east-1", AZCount: 2, ClusterVersion: "1.30", NodeSize: "t3.medium", NodeMin: 1, NodeMax: 3},
+	"gcp":   {Region: "us-central1", AZCount: 2, ClusterVersion: "latest", NodeSize: "e2-medium", NodeMin: 1, NodeMax: 3},
+	"azure": {Region: "eastus", AZCount: 2, ClusterVersion: "1.30", NodeSize: "Standard_D2s_v3", NodeMin: 1, NodeMax: 3},
+}
+
+// withInfraDefaults fills unset Terraform inputs from infraDefaults.
+func withInfraDefaults(data config.TemplateData) config.TemplateData {
+	def := infraDefaults[data.Provider]
+	if data.Region == "" {
+		data.Region = def.Region
+	}
+	if data.AZCount == 0 {
+		data.AZCount = def.AZCount
+	}
+	if data.ClusterVersion == "" {
+		data.ClusterVersion = def.ClusterVersion
+	}
+	if data.NodeSize == "" {
+		data.NodeSize = def.NodeSize
+	}
+	if data.NodeMin == 0 {
+		data.NodeMin = def.NodeMin
+	}
+	if data.NodeMax == 0 {
+		data.NodeMax = def.NodeMax
+	}
+	return data
+}
+
+// computeTargets lists the compute options per provider. kubernetes renders
+// the cluster in main.tf.tmpl; the others render <compute>.tf.tmpl as main.tf.
+var computeTargets = map[string][]string{
+	"aws":   {"kubernetes", "ecs-fargate", "app-runner", "lambda-container"},
+	"gcp":   {"kubernetes", "cloud-run"},
+	"azure": {"kubernetes", "container-apps"},
+}
+
+// computeTemplate returns the template rendered as main.tf for data.Compute.
+func computeTemplate(data config.TemplateData) (string, error) {
+	if data.Compute == "" || data.Compute == "kubernetes" {
+		return "main.tf.tmpl", nil
+	}
+	for _, c := range computeTargets[data.Provider] {
+		if c == data.Compute {
+			return c + ".tf.tmpl", nil
+		}
+	}
+	return "", fmt.Errorf("compute %q is not available on %s (%s)", data.Compute, data.Provider, strings.Join(computeTargets[data.Provider], ", "))
+}
+
+// infraData is the rendering context for the provider module. Database is
+// set when db in .exo.yaml has a managed equivalent, which adds database.tf;
+// Kubernetes compute also gets identity.tf for the app's ServiceAccount, and
+// CIRepo is set when the CI tool can sign in by OIDC, which adds ci.tf.
+type infraData struct {
+	config.TemplateData
+	Database    *appDatabase
+	DBName      string // database created on the managed instance
+	ShortName   string // AppName squeezed for length-limited global names
+	ZoneEnv     string // environment whose state owns the DNS zone
+	RegistryEnv string // environment whose state owns the image registry
+	CIRepo      string // repository (owner/name) the CI identity trusts
+	// StateBucket and LockTable name the remote state the CI deploy role
+	// reads and writes; empty without a configured backend.
+	StateBucket string
+	LockTable   string
+}
+
+func newInfraData(cwd string, data config.TemplateData) infraData {
+	d := infraData{
+		TemplateData: withInfraDefaults(data),
+		DBName:       config.Alphanumeric(data.AppName, 63),
+		ShortName:    config.Alphanumeric(data.AppName, 15),
+		ZoneEnv:      data.ProdEnv(),
+		RegistryEnv:  data.ProdEnv(),
+	}
+	if db, ok := appDatabases[data.DB]; ok {
+		d.Database = &db
+	}
+	if data.CI == "github-actions" || data.CI == "gitlab-ci" {
+		d.CIRepo = ciRepository(cwd, data.AppName)
+	}
+	if st, err := newTFState(data); err == nil && data.StateBackend != "" {
+		d.StateBucket, d.LockTable = st.Bucket, st.LockTable
+	}
+	return d
+}
+
+// infraFiles lists the provider module's files, shared by 'exo gen infra',
+// 'exo init' and 'exo add infra'. tmplDir is relative to templates/.
+func infraFiles(tmplDir, outDir string, data infraData) ([]genFile, error) {
+	mainTmpl, err := computeTemplate(data.TemplateData)
+	if err != nil {
+		return nil, err
+	}
+	files := []genFile{{filepath.Join(tmplDir, mainTmpl), filepath.Join(outDir, "main.tf"), data}}
+	names := []string{"variables.tf", "provider.tf", "registry.tf", "outputs.tf", "terraform.tfvars"}
+	if data.Database != nil {
+		names = append(names, "database.tf")
+	}
+	if data.Domain != "" {
+		names = append(names, "dns.tf")
+	}
+	if data.WorkloadIdentity() {
+		names = append(names, "identity.tf")
+	}
+	if data.CIRepo != "" {
+		names = append(names, "ci.tf")
+	}
+	for _, f := range names {
+		files = append(files, genFile{filepath.Join(tmplDir, f+".tmpl"), filepath.Join(outDir, f), data})
+	}
+	return files, nil
+}
+
+// tfState is the rendering context for backend.tf, the bootstrap module and
+// the per-environment backend/var files.
+type tfState struct {
+	config.TemplateData
+	Bucket        string // S3/GCS bucket or Azure storage account
+	LockTable     string // DynamoDB table; GCS and Azure lock natively
+	ResourceGroup string // Azure only
+	Workspaces    bool
+	Env           string
+}
+
+// newTFState validates the configured backend against the provider and
+// derives the state storage names.
+func newTFState(data config.TemplateData) (tfState, error) {
+	st := tfState{
+		TemplateData: data,
+		Bucket:       data.AppName + "-tfstate",
+		LockTable:    data.AppName + "-tflock",
+	}
+	if want := stateBackends[data.Provider]; data.StateBackend != want {
+		return st, fmt.Errorf("terraform backend %q does not match provider %q (use %s)", data.StateBackend, data.Provider, want)
+	}
+	switch data.StateLayout {
+	case "", "directories":
+	case "workspaces":
+		st.Workspaces = true
+	default:
+		return st, fmt.Errorf("unknown terraform layout %q (directories, workspaces)", data.StateLayout)
+	}
+	if data.Provider == "azure" {
+		st.Bucket = azureStorageAccountName(data.AppName)
+		st.ResourceGroup = data.AppName + "-tfstate-rg"
+	}
+	return st, nil
+}
+
+// azureStorageAccountName squeezes appName into Azure's 3-24 lowercase
+// alphanumeric storage account naming rule.
+func azureStorageAccountName(appName string) string {
+	return config.Alphanumeric(appName, 17) + "tfstate"
+}
+
 func generateInfra(cwd string, data config.TemplateData, dryRun, force bool) error {
 	prov := data.Provider
 	if prov == "" || prov == "none" {
@@ -18,24 +183,57 @@ func generateInfra(cwd string, data config.TemplateData, dryRun, force bool) err
 	}
 
 	infraDir := filepath.Join(cwd, "infra", prov)
+	tmplDir := filepath.Join("terraform", prov)
+
+	files, err := infraFiles(tmplDir, infraDir, newInfraData(cwd, data))
+	if err != nil {
+		return err
+	}
+	data = withInfraDefaults(data)
+	if data.StateBackend != "" {
+		st, err := newTFState(data)
+		if err != nil {
+			return err
+		}
+		files = append(files,
+			genFile{filepath.Join(tmplDir, "backend.tf.tmpl"), filepath.Join(infraDir, "backend.tf"), st},
+			genFile{filepath.Join(tmplDir, "bootstrap.tf.tmpl"), filepath.Join(infraDir, "bootstrap", "main.tf"), st},
+		)
+		if !st.Workspaces {
+			for _, env := range data.Environments {
+				e := st
+				e.Env = env
+				envDir := filepath.Join(infraDir, "envs", env)
+				files = append(files,
+					genFile{filepath.Join(tmplDir, "backend.hcl.tmpl"), filepath.Join(envDir, "backend.hcl"), e},
+					genFile{filepath.Join(tmplDir, "env.tfvars.tmpl"), filepath.Join(envDir, "terraform.tfvars"), e},
+				)
+			}
+		}
+	}
 
 	var stop func(error)
 	if !dryRun {
 		stop = startSpinner(fmt.Sprintf("Generating Terraform (%s) → infra/%s/", prov, prov))
 	}
 
-	var genErr error
-	for _, f := range []string{"main.tf", "variables.tf", "provider.tf"} {
-		tmpl := filepath.Join("templates", "terraform", prov, f+".tmpl")
-		out := filepath.Join(infraDir, f)
-		if err := renderFile(tmpl, out, data, dryRun, force); err != nil {
-			genErr = fmt.Errorf("infra/%s/%s: %w", prov, f, err)
-			break
-		}
-	}
+	genErr := renderFiles(cwd, files, dryRun, force)
 
 	if !dryRun {
 		stop(genErr)
+		if genErr == nil && data.Registry == "" {
+			fmt.Println("  ℹ  set registry: in .exo.yaml to the registry_url output host so CI and k8s use it")
+		}
+		if genErr == nil && data.StateBackend != "" {
+			fmt.Printf("  ℹ  apply infra/%s/bootstrap/ once to create the %s state storage\n", prov, data.StateBackend)
+		}
+		if genErr == nil && len(data.Environments) > 1 {
+			shared := "registry"
+			if data.Domain != "" {
+				shared = "registry and DNS zone"
+			}
+			fmt.Printf("  ℹ  apply the %s environment first: it creates the %s the others look up\n", data.ProdEnv(), shared)
+		}
 	}
 	return genErr
 }
@@ -7,8 +7,57 @@ import (
 	"github.com/Harsh-BH/Exo/internal/config"
 )
 
-func generateK8s(cwd string, data config.TemplateData, dryRun, force bool) error {
+// k8sManifests are the base manifests shared by every k8s output format.
+var k8sManifests = []string{"deployment.yaml", "service.yaml", "ingress.yaml", "serviceaccount.yaml"}
+
+// strategyManifests returns the extra manifests for data.Strategy: an Argo
+// Rollout plus AnalysisTemplate, or a Flagger Canary plus MetricTemplates.
+// The Deployment stays in place as the workload both controllers drive.
+func strategyManifests(data config.TemplateData) ([]string, error) {
+	switch data.Strategy {
+	case "", "rolling":
+		return nil, nil
+	case "canary", "blue-green":
+	default:
+		return nil, fmt.Errorf("unknown strategy %q (rolling, canary, blue-green)", data.Strategy)
+	}
+	switch data.Rollouts {
+	case "", "argo-rollouts":
+		return []string{"rollout.yaml", "analysistemplate.yaml"}, nil
+	case "flagger":
+		return []string{"canary.yaml", "metrictemplate.yaml"}, nil
+	default:
+		return nil, fmt.Errorf("unknown strategy controller %q (argo-rollouts, flagger)", data.Rollouts)
+	}
+}
+
+// tlsManifests returns the cert-manager ClusterIssuer and Certificate emitted
+// when a domain is configured.
+func tlsManifests(data config.TemplateData) []string {
+	if data.Domain == "" {
+		return nil
+	}
+	return []string{"clusterissuer.yaml", "certificate.yaml"}
+}
+
+func generateK8s(cwd string, data config.TemplateData, format string, dryRun, force bool) error {
+	switch format {
+	case "", "manifests":
+		return generateK8sManifests(cwd, data, dryRun, force)
+	case "kustomize":
+		return generateKustomize(cwd, data, dryRun, force)
+	default:
+		return fmt.Errorf("unknown k8s format %q (manifests, kustomize)", format)
+	}
+}
+
+func generateK8sManifests(cwd string, data config.TemplateData, dryRun, force bool) error {
 	k8sDir := filepath.Join(cwd, "k8s")
+	extra, err := strategyManifests(data)
+	if err != nil {
+		return err
+	}
+	extra = append(extra, tlsManifests(data)...)
 
 	var stop func(error)
 	if !dryRun {
@@ -16,7 +65,7 @@ func generateK8s(cwd string, data config.TemplateData, dryRun, force bool) error
 	}
 
 	var genErr error
-	for _, f := range []string{"deployment.yaml", "service.yaml", "ingress.yaml"} {
+	for _, f := range append(k8sManifests[:len(k8sManifests):len(k8sManifests)], extra...) {
 		tmpl := filepath.Join("templates", "k8s", f+".tmpl")
 		out := filepath.Join(k8sDir, f)
 		if err := renderFile(tmpl, out, data, dryRun, force); err != nil {
@@ -30,3 +79,87 @@ func generateK8s(cwd string, data config.TemplateData, dryRun, force bool) error
 	}
 	return genErr
 }
+
+// envProfile holds per-environment sizing shared by Kustomize overlays and
+// GitOps deploy objects.
+type envProfile struct {
+	config.TemplateData
+	Env           string
+	Replicas      int
+	ImageTag      string // latest, which CI pushes next to the commit SHA
+	Host          string
+	CPURequest    string
+	MemoryRequest string
+	CPULimit      string
+	MemoryLimit   string
+}
+
+// newEnvProfile returns sizing defaults for env. Production gets more
+// replicas and headroom; every other environment gets a subdomain host.
+func newEnvProfile(data config.TemplateData, env string) envProfile {
+	o := envProfile{
+		TemplateData:  data,
+		Env:           env,
+		Replicas:      1,
+		ImageTag:      "latest",
+		Host:          fmt.Sprintf("%s.%s.%s", data.AppName, env, data.BaseDomain()),
+		CPURequest:    "100m",
+		MemoryRequest: "64Mi",
+		CPULimit:      "250m",
+		MemoryLimit:   "128Mi",
+	}
+	switch env {
+	case "staging":
+		o.Replicas = 2
+		o.CPURequest, o.MemoryRequest = "250m", "128Mi"
+		o.CPULimit, o.MemoryLimit = "500m", "256Mi"
+	case "prod", "production":
+		o.Replicas = 3
+		o.Host = fmt.Sprintf("%s.%s", data.AppName, data.BaseDomain())
+		o.CPURequest, o.MemoryRequest = "500m", "256Mi"
+		o.CPULimit, o.MemoryLimit = "1", "512Mi"
+	}
+	return o
+}
+
+// generateKustomize writes k8s/base/ (the regular manifests plus a
+// kustomization.yaml) and one k8s/overlays/<env>/ per configured environment.
+func generateKustomize(cwd string, data config.TemplateData, dryRun, force bool) error {
+	baseDir := filepath.Join(cwd, "k8s", "base")
+	extra, err := strategyManifests(data)
+	if err != nil {
+		return err
+	}
+	extra = append(extra, tlsManifests(data)...)
+	resources := append(k8sManifests[:len(k8sManifests):len(k8sManifests)], extra...)
+
+	var stop func(error)
+	if !dryRun {
+		stop = startSpinner("Generating Kustomize base + overlays → k8s/")
+	}
+
+	var files []genFile
+	for _, f := range resources {
+		files = append(files, genFile{filepath.Join("k8s", f+".tmpl"), filepath.Join(baseDir, f), data})
+	}
+	base := struct {
+		config.TemplateData
+		Resources []string
+	}{data, resources}
+	files = append(files, genFile{filepath.Join("k8s", "kustomize", "base-kustomization.yaml.tmpl"), filepath.Join(baseDir, "kustomization.yaml"), base})
+
+	for _, env := range data.Environments {
+		overlay := newEnvProfile(data, env)
+		envDir := filepath.Join(cwd, "k8s", "overlays", env)
+		for _, f := range []string{"kustomization.yaml", "deployment-patch.yaml"} {
+			files = append(files, genFile{filepath.Join("k8s", "kustomize", "overlay-"+f+".tmpl"), filepath.Join(envDir, f), overlay})
+		}
+	}
+
+	genErr := renderFiles(cwd, files, dryRun, force)
+
+	if !dryRun {
+		stop(genErr)
+	}
+	return genErr
+}
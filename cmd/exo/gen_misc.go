@@ -3,14 +3,40 @@ package exo
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/Harsh-BH/Exo/internal/config"
 )
 
+// makefileTemplates maps stacks to their Makefile template, keyed like
+// dockerTemplates. Anything else gets the Go one.
+var makefileTemplates = map[string]string{
+	"go":             "Makefile.tmpl",
+	"node":           "node.tmpl",
+	"node/nextjs":    "nextjs.tmpl",
+	"node/nestjs":    "nestjs.tmpl",
+	"python":         "python.tmpl",
+	"python/django":  "django.tmpl",
+	"python/fastapi": "fastapi.tmpl",
+}
+
+// stackData adds the server entry point to TemplateData for templates that
+// run the app.
+type stackData struct {
+	config.TemplateData
+	Main string
+}
+
+// makefileFile returns the Makefile to render for data's stack.
+func makefileFile(cwd string, data config.TemplateData) genFile {
+	main, _ := stackEntry(cwd, data)
+	tmpl := stackTemplate(makefileTemplates, data, makefileTemplates["go"])
+	return genFile{filepath.Join("makefile", tmpl), filepath.Join(cwd, "Makefile"), stackData{data, main}}
+}
+
 func generateMakefile(cwd string, data config.TemplateData, dryRun, force bool) error {
-	tmpl := filepath.Join("templates", "makefile", "Makefile.tmpl")
-	out := filepath.Join(cwd, "Makefile")
-	if err := renderFile(tmpl, out, data, dryRun, force); err != nil {
+	f := makefileFile(cwd, data)
+	if err := renderFiles(cwd, []genFile{f}, dryRun, force); err != nil {
 		return fmt.Errorf("makefile: %w", err)
 	}
 	if !dryRun {
@@ -55,14 +81,29 @@ func generateGrafana(cwd string, data config.TemplateData, dryRun, force bool) e
 	return nil
 }
 
+// generateAlerts writes the alert rules and Alertmanager routing into
+// monitoring/, along with the scrape config that loads the rules and points
+// Prometheus at Alertmanager.
 func generateAlerts(cwd string, data config.TemplateData, dryRun, force bool) error {
-	tmpl := filepath.Join("templates", "alerts", "alerts.yml.tmpl")
-	out := filepath.Join(cwd, "alerts.yml")
-	if err := renderFile(tmpl, out, data, dryRun, force); err != nil {
+	if !data.Monitoring.Enabled() {
+		data.Monitoring.Metrics = "prometheus"
+	}
+	files, err := alertFiles(filepath.Join(cwd, "monitoring"), data)
+	if err != nil {
+		return fmt.Errorf("alerts: %w", err)
+	}
+	if err := renderFiles(cwd, files, dryRun, force); err != nil {
 		return fmt.Errorf("alerts: %w", err)
 	}
 	if !dryRun {
-		fmt.Printf("  ✓  Prometheus alerts → alerts.yml\n")
+		receivers := "no receivers"
+		if len(data.Alerts.Receivers) > 0 {
+			receivers = strings.Join(data.Alerts.Receivers, ", ")
+		}
+		fmt.Printf("  ✓  Alert rules + Alertmanager (%s) → monitoring/rules/alerts.yml, monitoring/alertmanager.yml, monitoring/prometheus.yml\n", receivers)
+		if secrets := data.Alerts.Secrets(); len(secrets) > 0 {
+			fmt.Printf("  ℹ  set %s in .env; docker compose hands them to Alertmanager as secrets\n", strings.Join(secrets, ", "))
+		}
 	}
 	return nil
 }
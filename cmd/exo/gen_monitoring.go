@@ -0,0 +1,117 @@
+package exo
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Harsh-BH/Exo/internal/config"
+)
+
+// monitoringNames are the display names of monitoring components.
+var monitoringNames = map[string]string{
+	"prometheus":      "Prometheus",
+	"victoriametrics": "VictoriaMetrics",
+	"loki":            "Loki",
+	"tempo":           "Tempo",
+	"jaeger":          "Jaeger",
+	"otel-collector":  "OTel Collector",
+}
+
+// monitoringLabel describes the stack for progress output, e.g.
+// "Prometheus + Loki + Tempo + Grafana".
+func monitoringLabel(m config.MonitoringConfig) string {
+	var names []string
+	for _, c := range m.Components() {
+		names = append(names, monitoringNames[c])
+	}
+	return strings.Join(append(names, "Grafana"), " + ")
+}
+
+// monitoringServices lists the compose services running m in start order.
+// Alerts are evaluated by Prometheus itself or by vmalert next to
+// VictoriaMetrics; Loki's logs are shipped by promtail; Grafana comes last as
+// it waits on the backends it reads from.
+func monitoringServices(m config.MonitoringConfig) []string {
+	if !m.Enabled() {
+		return nil
+	}
+	services := []string{m.Metrics}
+	if m.Metrics == "victoriametrics" {
+		services = append(services, "vmalert")
+	}
+	services = append(services, "alertmanager")
+	if m.Logs != "" {
+		services = append(services, "loki", "promtail")
+	}
+	if m.Traces != "" {
+		services = append(services, m.Traces)
+	}
+	if m.Collector {
+		services = append(services, "otel-collector")
+	}
+	return append(services, "grafana")
+}
+
+// alertmanagerData is the rendering context for alertmanager.tmpl.
+type alertmanagerData struct {
+	config.TemplateData
+	SecretsDir string // where Alertmanager finds one file per receiver secret
+}
+
+// alertFiles returns the alert rules, the Alertmanager routing and the scrape
+// config that loads the rules, written under dir.
+func alertFiles(dir string, data config.TemplateData) ([]genFile, error) {
+	if err := data.Alerts.Validate(); err != nil {
+		return nil, err
+	}
+	return []genFile{
+		{"monitoring/prometheus.tmpl", filepath.Join(dir, "prometheus.yml"), data},
+		{"alerts/alerts.yml.tmpl", filepath.Join(dir, "rules", "alerts.yml"), data},
+		{"monitoring/alertmanager.tmpl", filepath.Join(dir, "alertmanager.yml"), alertmanagerData{data, "/run/secrets"}},
+	}, nil
+}
+
+// monitoringConfigs returns the config files the monitoring services mount,
+// written under dir: the scrape config (read by VictoriaMetrics too), alert
+// rules and routing, the promtail, Tempo and collector configs the stack
+// needs, and Grafana's provisioned datasources and dashboard.
+func monitoringConfigs(dir string, data config.TemplateData) ([]genFile, error) {
+	m := data.Monitoring
+	if !m.Enabled() {
+		return nil, nil
+	}
+	files, err := alertFiles(dir, data)
+	if err != nil {
+		return nil, fmt.Errorf("alerts: %w", err)
+	}
+	if m.Logs != "" {
+		files = append(files, genFile{"monitoring/promtail.tmpl", filepath.Join(dir, "promtail.yml"), data})
+	}
+	if m.Traces == "tempo" {
+		files = append(files, genFile{"monitoring/tempo.tmpl", filepath.Join(dir, "tempo.yml"), data})
+	}
+	if m.Collector {
+		files = append(files, genFile{"monitoring/otel-collector.tmpl", filepath.Join(dir, "otel-collector.yml"), data})
+	}
+	grafana := filepath.Join(dir, "grafana")
+	return append(files,
+		genFile{"monitoring/grafana-datasources.tmpl", filepath.Join(grafana, "provisioning", "datasources", "datasources.yml"), data},
+		genFile{"monitoring/grafana-dashboards.tmpl", filepath.Join(grafana, "provisioning", "dashboards", "dashboards.yml"), data},
+		genFile{"grafana/dashboard.json.tmpl", filepath.Join(grafana, "dashboards", data.AppName+".json"), data},
+	), nil
+}
+
+// monitoringHelmDependencies are the subcharts running each component
+// in-cluster. Grafana comes with either metrics stack.
+var monitoringHelmDependencies = map[string][]helmDependency{
+	"prometheus":      {{"kube-prometheus-stack", "~65.0", "https://prometheus-community.github.io/helm-charts"}},
+	"victoriametrics": {{"victoria-metrics-k8s-stack", "~0.27", "https://victoriametrics.github.io/helm-charts"}},
+	"loki": {
+		{"loki", "~6.18", "https://grafana.github.io/helm-charts"},
+		{"promtail", "~6.16", "https://grafana.github.io/helm-charts"},
+	},
+	"tempo":          {{"tempo", "~1.10", "https://grafana.github.io/helm-charts"}},
+	"jaeger":         {{"jaeger", "~3.3", "https://jaegertracing.github.io/helm-charts"}},
+	"otel-collector": {{"opentelemetry-collector", "~0.108", "https://open-telemetry.github.io/opentelemetry-helm-charts"}},
+}
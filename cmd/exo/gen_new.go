@@ -2,8 +2,11 @@ package exo
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -124,34 +127,136 @@ func generatePreCommit(cwd string, data config.TemplateData, dryRun, force bool)
 
 // ─── DEVCONTAINER ─────────────────────────────────────────────────────────────
 
+// devcontainerStack is the toolchain image and editor setup for one stack.
+// Image is untagged; the tag is the project's toolchain version, or Version
+// when none is pinned.
+type devcontainerStack struct {
+	Image      string
+	Version    string
+	PostCreate string
+	Extensions []string
+}
+
+// devcontainerStacks is keyed like dockerTemplates; "" covers languages
+// without a dev container image of their own.
+var devcontainerStacks = map[string]devcontainerStack{
+	"go":             {"mcr.microsoft.com/devcontainers/go", "1.22", "go mod download", []string{"golang.go"}},
+	"node":           {"mcr.microsoft.com/devcontainers/javascript-node", "20", "npm install", []string{"esbenp.prettier-vscode", "dbaeumer.vscode-eslint"}},
+	"node/nextjs":    {"mcr.microsoft.com/devcontainers/javascript-node", "20", "npm install", []string{"esbenp.prettier-vscode", "dbaeumer.vscode-eslint", "bradlc.vscode-tailwindcss"}},
+	"node/nestjs":    {"mcr.microsoft.com/devcontainers/typescript-node", "20", "npm install", []string{"esbenp.prettier-vscode", "dbaeumer.vscode-eslint", "orta.vscode-jest"}},
+	"python":         {"mcr.microsoft.com/devcontainers/python", "3.12", "pip install -r requirements.txt", []string{"ms-python.python", "ms-python.black-formatter"}},
+	"python/django":  {"mcr.microsoft.com/devcontainers/python", "3.12", "pip install -r requirements.txt && python manage.py migrate", []string{"ms-python.python", "ms-python.black-formatter", "batisteo.vscode-django"}},
+	"python/fastapi": {"mcr.microsoft.com/devcontainers/python", "3.12", "pip install -r requirements.txt \"uvicorn[standard]\"", []string{"ms-python.python", "ms-python.black-formatter"}},
+	"":               {"mcr.microsoft.com/devcontainers/base", "ubuntu", "echo ready", nil},
+}
+
+// devcontainerTools installs the CLIs EXO's generated output relies on that
+// have no official dev container feature, from install scripts pinned to the
+// release they install so a moving branch can't change what runs under sudo.
+const devcontainerTools = "curl -sSfL https://raw.githubusercontent.com/aquasecurity/trivy/v0.56.2/contrib/install.sh | sudo sh -s -- -b /usr/local/bin v0.56.2" +
+	" && curl -sSfL https://raw.githubusercontent.com/anchore/syft/v1.14.0/install.sh | sudo sh -s -- -b /usr/local/bin v1.14.0"
+
+var (
+	goVersionRe     = regexp.MustCompile(`(?m)^go\s+(\d+\.\d+)`)
+	goToolchainRe   = regexp.MustCompile(`(?m)^toolchain\s+go(\d+\.\d+)`)
+	nodeEnginesRe   = regexp.MustCompile(`"node"\s*:\s*"[^"0-9]*(\d+)`)
+	requiresPyRe    = regexp.MustCompile(`requires-python\s*=\s*"[^"0-9]*(\d+\.\d+)`)
+	versionPrefixRe = regexp.MustCompile(`^v?(\d+(?:\.\d+)?)`)
+)
+
+// toolchainVersion returns the language version the project pins, trimmed to
+// what dev container image tags use (go 1.22, node 20, python 3.12), or "".
+// Go reads go.mod's toolchain then go directive; Node reads .nvmrc,
+// .node-version, then package.json engines; Python reads .python-version,
+// then pyproject.toml's requires-python.
+func toolchainVersion(cwd, lang string) string {
+	read := func(name string) string {
+		b, _ := os.ReadFile(filepath.Join(cwd, name))
+		return string(b)
+	}
+	first := func(name string) string {
+		m := versionPrefixRe.FindStringSubmatch(strings.TrimSpace(read(name)))
+		if m == nil {
+			return ""
+		}
+		return m[1]
+	}
+	match := func(re *regexp.Regexp, text string) string {
+		if m := re.FindStringSubmatch(text); m != nil {
+			return m[1]
+		}
+		return ""
+	}
+	switch lang {
+	case "go":
+		mod := read("go.mod")
+		if v := match(goToolchainRe, mod); v != "" {
+			return v
+		}
+		return match(goVersionRe, mod)
+	case "node":
+		for _, f := range []string{".nvmrc", ".node-version"} {
+			if v := first(f); v != "" {
+				return strings.SplitN(v, ".", 2)[0]
+			}
+		}
+		return match(nodeEnginesRe, read("package.json"))
+	case "python":
+		if v := first(".python-version"); strings.Contains(v, ".") {
+			return v
+		}
+		return match(requiresPyRe, read("pyproject.toml"))
+	}
+	return ""
+}
+
+// devcontainerPort is one forwarded port. Forward is the forwardPorts entry:
+// the bare port for the workspace, service:port for a sidecar.
+type devcontainerPort struct {
+	Forward string
+	Port    int
+	Label   string
+}
+
+// devcontainerData is the rendering context for devcontainerTmpl.
+type devcontainerData struct {
+	config.TemplateData
+	devcontainerStack
+	Services []string // compose services the container starts
+	Ports    []devcontainerPort
+	Tools    string
+}
+
 const devcontainerTmpl = `{
   "name": "{{.AppName}}",
-{{- if eq .Language "go"}}
-  "image": "mcr.microsoft.com/devcontainers/go:1.22",
-{{- else if eq .Language "node"}}
-  "image": "mcr.microsoft.com/devcontainers/javascript-node:20",
-{{- else if eq .Language "python"}}
-  "image": "mcr.microsoft.com/devcontainers/python:3.12",
-{{- else}}
-  "image": "mcr.microsoft.com/devcontainers/base:ubuntu",
-{{- end}}
+  "dockerComposeFile": "docker-compose.yml",
+  "service": "workspace",
+  "runServices": [{{range $i, $s := .Services}}{{if $i}}, {{end}}"{{$s}}"{{end}}],
+  "workspaceFolder": "/workspaces/{{.AppName}}",
+  "shutdownAction": "stopCompose",
   "features": {
     "ghcr.io/devcontainers/features/docker-in-docker:2": {},
-    "ghcr.io/devcontainers/features/git:1": {}
+    "ghcr.io/devcontainers/features/git:1": {},
+    "ghcr.io/devcontainers/features/terraform:1": {},
+    "ghcr.io/devcontainers/features/kubectl-helm-minikube:1": {
+      "minikube": "none"
+    }
   },
-  "forwardPorts": [{{.Port}}],
-  "postCreateCommand": "{{- if eq .Language "go"}}go mod download{{- else if eq .Language "node"}}npm install{{- else if eq .Language "python"}}pip install -r requirements.txt{{- else}}echo ready{{- end}}",
+  "forwardPorts": [{{range $i, $p := .Ports}}{{if $i}}, {{end}}{{if eq $p.Label "app"}}{{$p.Port}}{{else}}"{{$p.Forward}}"{{end}}{{end}}],
+  "portsAttributes": {
+{{- range $i, $p := .Ports}}{{if $i}},{{end}}
+    "{{$p.Port}}": {
+      "label": "{{$p.Label}}"
+    }
+{{- end}}
+  },
+  "onCreateCommand": {{printf "%q" .Tools}},
+  "postCreateCommand": {{printf "%q" .PostCreate}},
   "customizations": {
     "vscode": {
       "extensions": [
-{{- if eq .Language "go"}}
-        "golang.go"
-{{- else if eq .Language "node"}}
-        "esbenp.prettier-vscode",
-        "dbaeumer.vscode-eslint"
-{{- else if eq .Language "python"}}
-        "ms-python.python",
-        "ms-python.black-formatter"
+{{- range $i, $ext := .Extensions}}{{if $i}},{{end}}
+        "{{$ext}}"
 {{- end}}
       ]
     }
@@ -159,14 +264,80 @@ const devcontainerTmpl = `{
 }
 `
 
+// devcontainerPorts lists the sidecar services' ports for forwarding;
+// services without a UI or API of their own (promtail) are left out, as are
+// ports the app or an earlier sidecar already labels (grafana with an app on
+// 3000), since portsAttributes is keyed by port.
+var devcontainerPorts = map[string]int{
+	"postgres":        5432,
+	"mysql":           3306,
+	"mongo":           27017,
+	"redis":           6379,
+	"prometheus":      9090,
+	"victoriametrics": 8428,
+	"loki":            3100,
+	"tempo":           3200,
+	"jaeger":          16686,
+	"otel-collector":  4318,
+	"alertmanager":    9093,
+	"grafana":         3000,
+}
+
 func generateDevcontainer(cwd string, data config.TemplateData, dryRun, force bool) error {
+	stack, ok := devcontainerStacks[data.Language+"/"+data.Framework]
+	if !ok {
+		stack = devcontainerStacks[data.Language]
+	}
+	if stack.Image == "" {
+		stack = devcontainerStacks[""]
+	}
+	version := toolchainVersion(cwd, data.Language)
+	if version != "" && stack.Image != devcontainerStacks[""].Image {
+		stack.Version = version
+	}
+
+	// The workspace and its sidecars come from the same fragments as
+	// docker-compose.yml, without host ports so both can run at once.
+	c := newComposeData(data, "docker-compose.yml", data.AppName+"-devcontainer", "../monitoring")
+	c.Internal = true
+	c.Image = stack.Image + ":" + stack.Version
+	services := []string{"workspace"}
+	if c.Database != nil {
+		services = append(services, data.DB)
+	}
+	services = append(services, monitoringServices(data.Monitoring)...)
+	c, err := c.withServices(services...)
+	if err != nil {
+		return fmt.Errorf("devcontainer: %w", err)
+	}
+
+	d := devcontainerData{TemplateData: data, devcontainerStack: stack, Services: services, Tools: devcontainerTools}
+	d.Ports = append(d.Ports, devcontainerPort{strconv.Itoa(data.Port), data.Port, "app"})
+	labelled := map[int]bool{data.Port: true}
+	for _, s := range services[1:] {
+		port := devcontainerPorts[s]
+		if port == 0 || labelled[port] {
+			continue
+		}
+		labelled[port] = true
+		d.Ports = append(d.Ports, devcontainerPort{fmt.Sprintf("%s:%d", s, port), port, s})
+	}
+
 	dir := filepath.Join(cwd, ".devcontainer")
-	out := filepath.Join(dir, "devcontainer.json")
-	if err := renderer.RenderTemplateString(devcontainerTmpl, out, data, dryRun, force); err != nil {
+	files := []genFile{{"compose/compose.tmpl", filepath.Join(dir, "docker-compose.yml"), c}}
+	configs, err := monitoringConfigs(filepath.Join(cwd, "monitoring"), data)
+	if err != nil {
+		return fmt.Errorf("devcontainer: %w", err)
+	}
+	files = append(files, configs...)
+	if err := renderer.RenderTemplateString(devcontainerTmpl, filepath.Join(dir, "devcontainer.json"), d, dryRun, force); err != nil {
+		return fmt.Errorf("devcontainer: %w", err)
+	}
+	if err := renderFiles(cwd, files, dryRun, force); err != nil {
 		return fmt.Errorf("devcontainer: %w", err)
 	}
 	if !dryRun {
-		fmt.Println("  ✓  Dev container → .devcontainer/devcontainer.json")
+		fmt.Printf("  ✓  Dev container (%s, %s) → .devcontainer/devcontainer.json, .devcontainer/docker-compose.yml\n", c.Image, strings.Join(services, ", "))
 	}
 	return nil
 }
@@ -0,0 +1,157 @@
+package exo
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Harsh-BH/Exo/internal/config"
+)
+
+// releaseTools maps each release tool to the files it is configured by,
+// relative to the repo root. tmpl is relative to templates/release/.
+var releaseTools = map[string][]struct{ tmpl, out string }{
+	"goreleaser": {
+		{"goreleaser.yaml.tmpl", ".goreleaser.yaml"},
+		{"Dockerfile.goreleaser.tmpl", "Dockerfile.goreleaser"},
+	},
+	"semantic-release": {
+		{"releaserc.json.tmpl", ".releaserc.json"},
+	},
+	"changesets": {
+		{"changeset-config.json.tmpl", filepath.Join(".changeset", "config.json")},
+	},
+	"release-please": {
+		{"release-please-config.json.tmpl", "release-please-config.json"},
+		{"release-please-manifest.json.tmpl", ".release-please-manifest.json"},
+	},
+}
+
+// defaultReleaseTools picks the tool each language's ecosystem expects.
+var defaultReleaseTools = map[string]string{
+	"go":     "goreleaser",
+	"node":   "semantic-release",
+	"python": "release-please",
+}
+
+// releaseData is the rendering context for the release templates.
+type releaseData struct {
+	config.TemplateData
+	Tool        string
+	Repo        string // owner/name on Forge
+	Forge       string // github.com or gitlab.com
+	Owner       string // account holding the homebrew-tap repository
+	TapToken    string // variable holding the homebrew-tap token
+	Main        string // Go main package goreleaser builds
+	ReleaseType string // release-please strategy for the language
+}
+
+// releasePleaseTypes maps languages to release-please release types.
+var releasePleaseTypes = map[string]string{
+	"go":     "go",
+	"node":   "node",
+	"python": "python",
+	"java":   "maven",
+	"rust":   "rust",
+}
+
+func generateRelease(cwd string, data config.TemplateData, tool string, dryRun, force bool) error {
+	if tool == "" {
+		tool = defaultReleaseTools[data.Language]
+		if tool == "" {
+			tool = "release-please"
+		}
+	}
+	if _, ok := releaseTools[tool]; !ok {
+		return fmt.Errorf("unknown release tool %q (goreleaser, semantic-release, changesets, release-please)", tool)
+	}
+	if tool == "goreleaser" && data.Language != "go" {
+		return fmt.Errorf("goreleaser builds Go binaries; use semantic-release, changesets or release-please for %s", data.Language)
+	}
+	if (tool == "semantic-release" || tool == "changesets") && data.Language != "node" {
+		return fmt.Errorf("%s reads package.json; use release-please for %s", tool, data.Language)
+	}
+
+	// GitHub gets a publish workflow of its own. On GitLab the release jobs
+	// live in .gitlab-ci.yml, which exo gen ci owns; the tools that drive
+	// GitHub pull requests have no GitLab counterpart.
+	ci := data.CI
+	_, otherCI := ciTargets[ci]
+	switch {
+	case ci == "gitlab-ci" && (tool == "release-please" || tool == "changesets"):
+		return fmt.Errorf("%s opens GitHub pull requests; on GitLab CI use goreleaser (Go) or semantic-release (Node), or push v* tags yourself: exo gen ci publishes them", tool)
+	case ci == "gitlab-ci" && data.RegistryKind() == "acr":
+		return fmt.Errorf("GitLab tag pipelines cannot push to ACR: Azure federated credentials cannot match tag refs")
+	case otherCI && ci != "gitlab-ci":
+		return fmt.Errorf("release pipelines support github-actions and gitlab-ci, not %s", ci)
+	}
+
+	d := releaseData{
+		TemplateData: data,
+		Tool:         tool,
+		Repo:         ciRepository(cwd, data.AppName),
+		Main:         goMainPackage(cwd, data.AppName),
+		ReleaseType:  releasePleaseTypes[data.Language],
+		Forge:        "github.com",
+		TapToken:     "HOMEBREW_TAP_GITHUB_TOKEN",
+	}
+	if otherCI {
+		d.Forge, d.TapToken = "gitlab.com", "HOMEBREW_TAP_TOKEN"
+	}
+	d.Owner = strings.SplitN(d.Repo, "/", 2)[0]
+	if d.ReleaseType == "" {
+		d.ReleaseType = "simple"
+	}
+
+	var files []genFile
+	for _, f := range releaseTools[tool] {
+		// GitLab builds release images in the pipeline, from the Dockerfile.
+		if otherCI && f.out == "Dockerfile.goreleaser" {
+			continue
+		}
+		files = append(files, genFile{filepath.Join("release", f.tmpl), filepath.Join(cwd, f.out), d})
+	}
+	pipeline := filepath.Join(cwd, ciPath(ci, "release"))
+	existing := otherCI && fileExists(pipeline)
+	switch {
+	case !otherCI:
+		files = append(files, genFile{"ci/github-release.tmpl", pipeline, d})
+	case !existing:
+		cd := newCIData(cwd, data)
+		cd.Release = tool
+		files = append(files, genFile{filepath.Join("ci", ciTargets[ci].Tmpl), pipeline, cd})
+	}
+	if err := renderFiles(cwd, files, dryRun, force); err != nil {
+		return fmt.Errorf("release: %w", err)
+	}
+	if dryRun {
+		return nil
+	}
+
+	var written []string
+	for _, f := range files {
+		rel, _ := filepath.Rel(cwd, f.out)
+		written = append(written, filepath.ToSlash(rel))
+	}
+	fmt.Printf("  ✓  Release (%s) → %s\n", tool, strings.Join(written, ", "))
+	if otherCI {
+		if existing {
+			fmt.Printf("  ℹ  run 'exo gen ci --force' (with --deploy if it has deploy jobs) to add the release jobs to %s\n", ciPath(ci, "release"))
+		}
+		if tool == "goreleaser" {
+			fmt.Printf("  ℹ  add GITLAB_TOKEN (api scope) and HOMEBREW_TAP_TOKEN CI/CD variables; the tap token needs write access to %s/homebrew-tap\n", d.Owner)
+		} else {
+			fmt.Println("  ℹ  add a GITLAB_TOKEN CI/CD variable (api scope) so semantic-release can tag releases")
+		}
+		if data.Registry != "" {
+			fmt.Printf("  ℹ  protect v* tags: tag pipelines push release images to %s\n", data.RegistryHost())
+		}
+		return nil
+	}
+	if tool == "goreleaser" {
+		fmt.Printf("  ℹ  add a HOMEBREW_TAP_GITHUB_TOKEN secret with write access to %s/homebrew-tap\n", d.Owner)
+	} else {
+		fmt.Println("  ℹ  add a RELEASE_TOKEN secret so release tags trigger the publish job")
+	}
+	return nil
+}
@@ -2,13 +2,17 @@ package exo
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/Harsh-BH/Exo/internal/config"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 func executeCommand(root *cobra.Command, args ...string) (string, error) {
@@ -55,7 +59,7 @@ func testData() config.TemplateData {
 		DB:         "postgres",
 		Provider:   "aws",
 		CI:         "github",
-		Monitoring: "prometheus",
+		Monitoring: config.MonitoringConfig{Metrics: "prometheus"},
 		Registry:   "ghcr.io/testapp",
 	}
 }
@@ -182,3 +186,2081 @@ func TestGenerateSonarqube_Python(t *testing.T) {
 		t.Error("expected python coverage path in sonar config")
 	}
 }
+
+// ─── Docker ──────────────────────────────────────────────────────────────────
+
+func TestGenerateDockerfile_Hardened(t *testing.T) {
+	dir := t.TempDir()
+	if err := generateDockerfile(dir, testData(), false, false); err != nil {
+		t.Fatalf("generateDockerfile error: %v", err)
+	}
+	content := readGenerated(t, dir, "Dockerfile")
+	assertContains(t, "Dockerfile", content,
+		"# syntax=docker/dockerfile:1",
+		"CGO_ENABLED=0",
+		"-trimpath",
+		"--mount=type=cache,target=/root/.cache/go-build",
+		"FROM alpine:3.20",
+		"USER 65532:65532",
+		"HEALTHCHECK",
+		"org.opencontainers.image.title=\"testapp\"",
+	)
+	if _, err := os.Stat(filepath.Join(dir, ".dockerignore")); err != nil {
+		t.Error(".dockerignore not created")
+	}
+}
+
+func TestGenerateDockerfile_Bases(t *testing.T) {
+	cases := []struct {
+		lang, base, runtime string
+		healthcheck         bool
+	}{
+		{"go", "debian-slim", "FROM debian:bookworm-slim", true},
+		{"go", "distroless", "FROM gcr.io/distroless/static-debian12:nonroot", false},
+		{"go", "chainguard", "FROM cgr.dev/chainguard/static:latest", false},
+		{"go", "scratch", "FROM scratch", false},
+		{"node", "alpine", "FROM node:20-alpine\n", true},
+		{"node", "distroless", "FROM gcr.io/distroless/nodejs20-debian12:nonroot", true},
+		{"python", "debian-slim", "FROM python:3.12-slim-bookworm\n", true},
+		{"python", "chainguard", "FROM cgr.dev/chainguard/python:latest\n", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.lang+"/"+tc.base, func(t *testing.T) {
+			dir := t.TempDir()
+			d := testData()
+			d.Language, d.Docker.Base = tc.lang, tc.base
+			if err := generateDockerfile(dir, d, false, false); err != nil {
+				t.Fatalf("generateDockerfile error: %v", err)
+			}
+			content := readGenerated(t, dir, "Dockerfile")
+			if !bytes.Contains(content, []byte(tc.runtime)) {
+				t.Errorf("Dockerfile missing %q", tc.runtime)
+			}
+			if got := bytes.Contains(content, []byte("HEALTHCHECK --interval")); got != tc.healthcheck {
+				t.Errorf("HEALTHCHECK present = %v, want %v", got, tc.healthcheck)
+			}
+			if !bytes.Contains(content, []byte("USER 65532:65532")) {
+				t.Error("Dockerfile runs as root")
+			}
+		})
+	}
+}
+
+func TestGenerateDockerfile_PinnedToolchain(t *testing.T) {
+	cases := []struct {
+		lang, base, file, pin string
+		want                  []string
+	}{
+		{"go", "alpine", "go.mod", "module x\n\ngo 1.23.4\n", []string{"golang:1.23-alpine AS source", "FROM alpine:3.20"}},
+		{"node", "debian-slim", ".nvmrc", "22.3.0\n", []string{"FROM node:22-bookworm-slim AS deps", "FROM node:22-bookworm-slim\n"}},
+		// distroless can't follow the pin, so it keeps the builder it matches.
+		{"node", "distroless", ".nvmrc", "22\n", []string{"FROM node:20-bookworm-slim AS deps", "nodejs20-debian12"}},
+		{"python", "alpine", ".python-version", "3.13.1\n", []string{"FROM python:3.13-alpine AS builder", "FROM python:3.13-alpine\n"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.lang+"/"+tc.base, func(t *testing.T) {
+			dir := t.TempDir()
+			os.WriteFile(filepath.Join(dir, tc.file), []byte(tc.pin), 0644)
+			d := testData()
+			d.Language, d.Docker.Base = tc.lang, tc.base
+			if err := generateDockerfile(dir, d, false, false); err != nil {
+				t.Fatalf("generateDockerfile error: %v", err)
+			}
+			content := readGenerated(t, dir, "Dockerfile")
+			assertContains(t, "Dockerfile", content, tc.want...)
+		})
+	}
+}
+
+func TestGenerateDockerfile_Options(t *testing.T) {
+	stub := resolveDigest
+	defer func() { resolveDigest = stub }()
+	resolveDigest = func(ref string) (string, error) { return "sha256:feed", nil }
+
+	off := false
+	dir := t.TempDir()
+	d := testData()
+	d.Language = "python"
+	d.Docker = config.DockerConfig{
+		User:         "root",
+		Healthcheck:  "none",
+		CacheMounts:  &off,
+		Dockerignore: &off,
+		Pin:          true,
+		Digests:      map[string]string{"python:3.12-alpine": "sha256:beef"},
+		Labels:       map[string]string{"org.opencontainers.image.vendor": "Acme"},
+	}
+	if err := generateDockerfile(dir, d, false, false); err != nil {
+		t.Fatalf("generateDockerfile error: %v", err)
+	}
+	content := readGenerated(t, dir, "Dockerfile")
+	assertContains(t, "Dockerfile", content, "FROM python:3.12-alpine@sha256:beef AS builder", "FROM python:3.12-alpine@sha256:beef\n", "--no-cache-dir", `org.opencontainers.image.vendor="Acme"`)
+	for _, unwanted := range []string{"USER", "HEALTHCHECK", "--mount=type=cache"} {
+		if bytes.Contains(content, []byte(unwanted)) {
+			t.Errorf("Dockerfile should not contain %q", unwanted)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".dockerignore")); err == nil {
+		t.Error(".dockerignore written with docker.dockerignore: false")
+	}
+
+	d.Docker = config.DockerConfig{Base: "debian-slim", Pin: true}
+	if err := generateDockerfile(dir, d, false, true); err != nil {
+		t.Fatalf("generateDockerfile error: %v", err)
+	}
+	content = readGenerated(t, dir, "Dockerfile")
+	if !bytes.Contains(content, []byte("python:3.12-slim-bookworm@sha256:feed")) {
+		t.Error("docker.pin did not pin the resolved digest")
+	}
+}
+
+func TestGenerateDockerfile_Frameworks(t *testing.T) {
+	cases := []struct {
+		lang, framework string
+		files           map[string]string
+		want            []string
+	}{
+		{"node", "nextjs", nil, []string{"COPY --from=builder /app/.next/standalone ./", `ENTRYPOINT ["node", "server.js"]`, `CMD ["npx", "next", "dev"`}},
+		{"node", "nestjs", nil, []string{"npm run build && npm prune --omit=dev", `ENTRYPOINT ["node", "dist/main.js"]`, "FROM source AS dev"}},
+		{"python", "django", map[string]string{"manage.py": `os.environ.setdefault("DJANGO_SETTINGS_MODULE", "mysite.settings")`}, []string{"collectstatic --noinput", `"gunicorn", "mysite.wsgi:application"`}},
+		{"python", "fastapi", map[string]string{filepath.Join("app", "main.py"): ""}, []string{"uvicorn[standard]", `"uvicorn", "app.main:app"`, `"--port", "8080"`, `"--reload"]`}},
+		{"python", "flask", nil, []string{`ENTRYPOINT ["python", "app.py"]`}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.framework, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tc.files {
+				os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)
+				os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+			}
+			d := testData()
+			d.Language, d.Framework = tc.lang, tc.framework
+			if err := generateDockerfile(dir, d, false, false); err != nil {
+				t.Fatalf("generateDockerfile error: %v", err)
+			}
+			content := readGenerated(t, dir, "Dockerfile")
+			assertContains(t, "Dockerfile", content, tc.want...)
+		})
+	}
+}
+
+func TestGenerateDockerfile_Rejects(t *testing.T) {
+	cases := []config.DockerConfig{
+		{Base: "scratch"},
+		{Base: "ubuntu"},
+		{Base: "distroless", User: "app"},
+	}
+	for _, c := range cases {
+		d := testData()
+		d.Language, d.Docker = "node", c
+		if err := generateDockerfile(t.TempDir(), d, false, false); err == nil {
+			t.Errorf("expected docker %+v to be rejected", c)
+		}
+	}
+}
+
+func TestToolchainVersion(t *testing.T) {
+	cases := []struct{ lang, file, content, want string }{
+		{"go", "go.mod", "module x\n\ngo 1.22.3\n\ntoolchain go1.23.2\n", "1.23"},
+		{"node", ".nvmrc", "v18.19.0\n", "18"},
+		{"node", "package.json", `{"engines": {"node": ">=22.1"}}`, "22"},
+		{"python", "pyproject.toml", "requires-python = \">=3.11\"\n", "3.11"},
+		{"python", ".python-version", "3.13.0\n", "3.13"},
+	}
+	for _, c := range cases {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, c.file), []byte(c.content), 0644)
+		if got := toolchainVersion(dir, c.lang); got != c.want {
+			t.Errorf("%s %s: got %q, want %q", c.lang, c.file, got, c.want)
+		}
+	}
+}
+
+func TestGenerateDockerignore(t *testing.T) {
+	cases := []struct {
+		lang, base, lockfile string
+		want, unwanted       []string
+	}{
+		{"go", "", "", []string{"*.test", "k8s", "infra", ".github/workflows"}, []string{"node_modules", "README.md"}},
+		{"node", "", "pnpm-lock.yaml", []string{"node_modules", ".pnpm-store", "charts"}, []string{"__pycache__"}},
+		{"python", "", "poetry.lock", []string{"__pycache__", ".venv", "monitoring"}, []string{"node_modules"}},
+		// .dockerignore doesn't depend on the base, so one with no node
+		// runtime mustn't fail it.
+		{"node", "scratch", "", []string{"node_modules"}, []string{"__pycache__"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.lang+"/"+tc.base, func(t *testing.T) {
+			dir := t.TempDir()
+			if tc.lockfile != "" {
+				os.WriteFile(filepath.Join(dir, tc.lockfile), nil, 0644)
+			}
+			d := testData()
+			d.Language, d.Docker.Base = tc.lang, tc.base
+			if err := generateDockerignore(dir, d, false, false); err != nil {
+				t.Fatalf("generateDockerignore error: %v", err)
+			}
+			content := readGenerated(t, dir, ".dockerignore")
+			lines := map[string]bool{}
+			for _, l := range strings.Split(string(content), "\n") {
+				lines[l] = true
+			}
+			for _, want := range append([]string{".git", ".env"}, tc.want...) {
+				if !lines[want] {
+					t.Errorf(".dockerignore missing %q", want)
+				}
+			}
+			for _, unwanted := range tc.unwanted {
+				if lines[unwanted] {
+					t.Errorf(".dockerignore should not list %q", unwanted)
+				}
+			}
+		})
+	}
+}
+
+// ─── Dev environment ─────────────────────────────────────────────────────────
+
+func TestGenerateDevcontainer_PortCollision(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.Port = 3000
+	if err := generateDevcontainer(dir, d, false, false); err != nil {
+		t.Fatalf("generateDevcontainer error: %v", err)
+	}
+	raw := readGenerated(t, dir, ".devcontainer", "devcontainer.json")
+	var dc struct {
+		ForwardPorts    []interface{}                     `json:"forwardPorts"`
+		PortsAttributes map[string]struct{ Label string } `json:"portsAttributes"`
+		OnCreateCommand string                            `json:"onCreateCommand"`
+	}
+	if err := json.Unmarshal(raw, &dc); err != nil {
+		t.Fatalf("devcontainer.json is not valid JSON: %v", err)
+	}
+	// A duplicate key still parses, keeping only the last label.
+	if n := bytes.Count(raw, []byte(`"3000": {`)); n != 1 {
+		t.Errorf("portsAttributes labels 3000 %d times, want once", n)
+	}
+	if got := dc.PortsAttributes["3000"].Label; got != "app" {
+		t.Errorf("port 3000 labelled %q, want app", got)
+	}
+	for _, p := range dc.ForwardPorts {
+		if p == "grafana:3000" {
+			t.Error("grafana forwarded over the app's port")
+		}
+	}
+	if strings.Contains(dc.OnCreateCommand, "/main/") {
+		t.Error("tool install scripts should be pinned to a release")
+	}
+}
+
+func TestGenerateDevcontainer_Framework(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.Language, d.Framework = "python", "django"
+	if err := generateDevcontainer(dir, d, false, false); err != nil {
+		t.Fatalf("generateDevcontainer error: %v", err)
+	}
+	raw := readGenerated(t, dir, ".devcontainer", "devcontainer.json")
+	var dc struct {
+		Service    string `json:"service"`
+		PostCreate string `json:"postCreateCommand"`
+	}
+	if err := json.Unmarshal(raw, &dc); err != nil {
+		t.Fatalf("devcontainer.json is not valid JSON: %v", err)
+	}
+	if dc.Service != "workspace" || !strings.Contains(dc.PostCreate, "manage.py migrate") {
+		t.Errorf("devcontainer not tuned for django: %+v", dc)
+	}
+	compose := readGenerated(t, dir, ".devcontainer", "docker-compose.yml")
+	if !bytes.Contains(compose, []byte("mcr.microsoft.com/devcontainers/python:3.12")) {
+		t.Errorf("expected default python image in workspace:\n%s", compose)
+	}
+}
+
+func TestGenerateDevcontainer_ComposeAndPin(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module x\n\ngo 1.23.1\n"), 0644)
+	d := testData()
+	d.Language, d.DB = "go", "postgres"
+	if err := generateDevcontainer(dir, d, false, false); err != nil {
+		t.Fatalf("generateDevcontainer error: %v", err)
+	}
+	raw := readGenerated(t, dir, ".devcontainer", "devcontainer.json")
+	var dc struct {
+		RunServices  []string `json:"runServices"`
+		ForwardPorts []any    `json:"forwardPorts"`
+	}
+	if err := json.Unmarshal(raw, &dc); err != nil {
+		t.Fatalf("devcontainer.json is not valid JSON: %v", err)
+	}
+	if strings.Join(dc.RunServices, ",") != "workspace,postgres,prometheus,alertmanager,grafana" {
+		t.Errorf("runServices = %v", dc.RunServices)
+	}
+	if !bytes.Contains(raw, []byte(`"postgres:5432"`)) {
+		t.Errorf("forwardPorts = %v", dc.ForwardPorts)
+	}
+	compose, err := os.ReadFile(filepath.Join(dir, ".devcontainer", "docker-compose.yml"))
+	if err != nil {
+		t.Fatal(".devcontainer/docker-compose.yml not created")
+	}
+	var c struct {
+		Services map[string]struct {
+			Image string   `yaml:"image"`
+			Ports []string `yaml:"ports"`
+		} `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(compose, &c); err != nil {
+		t.Fatalf("devcontainer compose is not valid YAML: %v", err)
+	}
+	if img := c.Services["workspace"].Image; img != "mcr.microsoft.com/devcontainers/go:1.23" {
+		t.Errorf("workspace image = %q, want go.mod's version", img)
+	}
+	if len(c.Services["postgres"].Ports) != 0 {
+		t.Error("devcontainer sidecars should not publish host ports")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "monitoring", "prometheus.yml")); err != nil {
+		t.Error("monitoring/prometheus.yml not created")
+	}
+}
+
+func TestGenerateMakefile_Stacks(t *testing.T) {
+	cases := []struct {
+		lang, framework, want string
+	}{
+		{"go", "gin", "go build"},
+		{"node", "", "node --watch index.js"},
+		{"node", "nextjs", "npx next dev --port 8080"},
+		{"node", "nestjs", "npx nest start --watch"},
+		{"python", "", "$(PYTHON) -m pytest"},
+		{"python", "django", "manage.py runserver 0.0.0.0:8080"},
+		{"python", "fastapi", "uvicorn main:app --host 0.0.0.0 --port 8080 --reload"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.lang+"/"+tc.framework, func(t *testing.T) {
+			dir := t.TempDir()
+			d := testData()
+			d.Language, d.Framework = tc.lang, tc.framework
+			if err := generateMakefile(dir, d, false, false); err != nil {
+				t.Fatalf("generateMakefile error: %v", err)
+			}
+			content := readGenerated(t, dir, "Makefile")
+			if !bytes.Contains(content, []byte(tc.want)) {
+				t.Errorf("Makefile missing %q", tc.want)
+			}
+		})
+	}
+}
+
+func TestGenerateDevloop(t *testing.T) {
+	t.Run("tilt kind helm", func(t *testing.T) {
+		dir := t.TempDir()
+		d := testData()
+		if err := generateHelm(dir, d, false, false, false); err != nil {
+			t.Fatalf("generateHelm error: %v", err)
+		}
+		if err := generateDevloop(dir, d, "", "", false, false); err != nil {
+			t.Fatalf("generateDevloop error: %v", err)
+		}
+		tilt := readGenerated(t, dir, "Tiltfile")
+		assertContains(t, "Tiltfile", tilt, "target='dev'", "docker_build_with_restart", "helm(\n    'charts/testapp'", "devloop/values-dev.yaml", "allow_k8s_contexts('kind-testapp')")
+		info, err := os.Stat(filepath.Join(dir, "devloop", "cluster.sh"))
+		if err != nil || info.Mode()&0100 == 0 {
+			t.Error("devloop/cluster.sh missing or not executable")
+		}
+		kind := readGenerated(t, dir, "devloop", "kind.yaml")
+		if !bytes.Contains(kind, []byte("/etc/containerd/certs.d")) {
+			t.Error("kind.yaml does not configure the local registry")
+		}
+	})
+
+	t.Run("skaffold k3d kustomize", func(t *testing.T) {
+		dir := t.TempDir()
+		d := testData()
+		d.Language = "node"
+		d.Environments = []string{"dev", "prod"}
+		if err := generateK8s(dir, d, "kustomize", false, false); err != nil {
+			t.Fatalf("generateK8s error: %v", err)
+		}
+		if err := generateDevloop(dir, d, "skaffold", "k3d", false, false); err != nil {
+			t.Fatalf("generateDevloop error: %v", err)
+		}
+		skaffold := readGenerated(t, dir, "skaffold.yaml")
+		assertContains(t, "skaffold.yaml", skaffold, "target: dev", `src: "**/*.ts"`, "namespace: testapp-dev", "- devloop")
+		overlay := readGenerated(t, dir, "devloop", "kustomization.yaml")
+		if !bytes.Contains(overlay, []byte("- ../k8s/overlays/dev")) {
+			t.Errorf("devloop overlay does not build on the dev overlay:\n%s", overlay)
+		}
+		k3d := readGenerated(t, dir, "devloop", "k3d.yaml")
+		if !bytes.Contains(k3d, []byte("http://k3d-testapp-registry:5000")) {
+			t.Error("k3d.yaml does not mirror localhost to the registry")
+		}
+	})
+
+	t.Run("needs deploy output", func(t *testing.T) {
+		if err := generateDevloop(t.TempDir(), testData(), "", "", false, false); err == nil {
+			t.Error("expected an error without k8s/ or charts/ output")
+		}
+	})
+}
+
+// ─── Compose and monitoring ──────────────────────────────────────────────────
+
+func TestGenerateDockerCompose(t *testing.T) {
+	dir := t.TempDir()
+	if err := generateDockerCompose(dir, testData(), true, false, false); err != nil {
+		t.Fatalf("generateDockerCompose error: %v", err)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, "docker-compose.yml"))
+	if err != nil {
+		t.Fatalf("docker-compose.yml not created: %v", err)
+	}
+	if bytes.Contains(raw, []byte("version:")) {
+		t.Error("docker-compose.yml should not set the obsolete version key")
+	}
+	var compose struct {
+		Services map[string]struct {
+			Profiles    []string                     `yaml:"profiles"`
+			Networks    []string                     `yaml:"networks"`
+			DependsOn   map[string]map[string]string `yaml:"depends_on"`
+			Healthcheck struct {
+				Test []string `yaml:"test"`
+			} `yaml:"healthcheck"`
+		} `yaml:"services"`
+		Networks map[string]struct {
+			Name string `yaml:"name"`
+		} `yaml:"networks"`
+	}
+	if err := yaml.Unmarshal(raw, &compose); err != nil {
+		t.Fatalf("docker-compose.yml is not valid YAML: %v", err)
+	}
+	profiles := map[string]string{"app": "app", "postgres": "db", "prometheus": "monitoring", "grafana": "monitoring"}
+	for name, profile := range profiles {
+		svc, ok := compose.Services[name]
+		if !ok {
+			t.Errorf("service %s missing", name)
+			continue
+		}
+		if !strings.Contains(strings.Join(svc.Profiles, ","), profile) {
+			t.Errorf("service %s profiles = %v, want %s", name, svc.Profiles, profile)
+		}
+		if len(svc.Healthcheck.Test) == 0 {
+			t.Errorf("service %s has no healthcheck", name)
+		}
+	}
+	if got := compose.Services["app"].DependsOn["postgres"]["condition"]; got != "service_healthy" {
+		t.Errorf("app depends_on postgres condition = %q, want service_healthy", got)
+	}
+	if got := compose.Networks["backend"].Name; got != "testapp-backend" {
+		t.Errorf("backend network name = %q, want testapp-backend", got)
+	}
+	for _, f := range []string{"compose.override.yml", filepath.Join("monitoring", "prometheus.yml")} {
+		if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+			t.Errorf("%s not created", f)
+		}
+	}
+	override := readGenerated(t, dir, "compose.override.yml")
+	if !bytes.Contains(override, []byte("target: dev")) || !bytes.Contains(override, []byte("sync+restart")) {
+		t.Errorf("compose.override.yml does not run the source with reload:\n%s", override)
+	}
+}
+
+func TestGenerateDockerCompose_GrafanaPort(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.Port = 3000
+	if err := generateDockerCompose(dir, d, false, false, false); err != nil {
+		t.Fatalf("generateDockerCompose error: %v", err)
+	}
+	raw := readGenerated(t, dir, "docker-compose.yml")
+	for _, want := range []string{`"3000:3000"`, `"127.0.0.1:3001:3000"`} {
+		if !bytes.Contains(raw, []byte(want)) {
+			t.Errorf("docker-compose.yml missing %s", want)
+		}
+	}
+}
+
+func TestGenerateDockerCompose_Observability(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.Monitoring = config.MonitoringConfig{Metrics: "victoriametrics", Logs: "loki", Traces: "tempo", Collector: true}
+	if err := generateDockerCompose(dir, d, false, false, false); err != nil {
+		t.Fatalf("generateDockerCompose error: %v", err)
+	}
+	raw := readGenerated(t, dir, "docker-compose.yml")
+	var compose struct {
+		Services map[string]struct {
+			Environment map[string]string `yaml:"environment"`
+		} `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(raw, &compose); err != nil {
+		t.Fatalf("docker-compose.yml is not valid YAML: %v", err)
+	}
+	for _, name := range []string{"victoriametrics", "vmalert", "alertmanager", "loki", "promtail", "tempo", "otel-collector", "grafana"} {
+		if _, ok := compose.Services[name]; !ok {
+			t.Errorf("service %s missing", name)
+		}
+	}
+	if _, ok := compose.Services["prometheus"]; ok {
+		t.Error("prometheus should not run next to victoriametrics")
+	}
+	if got := compose.Services["app"].Environment["OTEL_EXPORTER_OTLP_ENDPOINT"]; got != "http://otel-collector:4318" {
+		t.Errorf("app OTLP endpoint = %q, want the collector", got)
+	}
+	mon := filepath.Join(dir, "monitoring")
+	for _, f := range []string{"prometheus.yml", "promtail.yml", "tempo.yml", "otel-collector.yml",
+		filepath.Join("grafana", "provisioning", "datasources", "datasources.yml"),
+		filepath.Join("grafana", "provisioning", "dashboards", "dashboards.yml"),
+		filepath.Join("grafana", "dashboards", "testapp.json")} {
+		if _, err := os.Stat(filepath.Join(mon, f)); err != nil {
+			t.Errorf("monitoring/%s not created", f)
+		}
+	}
+	scrape := readGenerated(t, mon, "prometheus.yml")
+	if !bytes.Contains(scrape, []byte("'app:8080'")) || !bytes.Contains(scrape, []byte("otel-collector:8889")) {
+		t.Errorf("scrape config should target the app service and the collector:\n%s", scrape)
+	}
+	datasources := readGenerated(t, mon, "grafana", "provisioning", "datasources", "datasources.yml")
+	assertContains(t, "datasources.yml", datasources, "http://victoriametrics:8428", "type: loki", "type: tempo")
+	dashboard := readGenerated(t, mon, "grafana", "dashboards", "testapp.json")
+	if !json.Valid(dashboard) {
+		t.Error("provisioned dashboard is not valid JSON")
+	}
+}
+
+func TestAddMonitoring_HostApp(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.Monitoring = config.MonitoringConfig{Metrics: "prometheus", Traces: "jaeger"}
+	files, err := monitoringFiles(dir, d)
+	if err != nil {
+		t.Fatalf("monitoringFiles error: %v", err)
+	}
+	if err := renderFiles(dir, files, false, false); err != nil {
+		t.Fatalf("render error: %v", err)
+	}
+	content := readGenerated(t, dir, "monitoring", "docker-compose.monitoring.yml")
+	assertContains(t, "docker-compose.monitoring.yml", content, "app:host-gateway", "jaegertracing/all-in-one", "127.0.0.1:4318:4318")
+}
+
+func TestGenerateAlerts_Receivers(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.Alerts = config.AlertsConfig{Receivers: []string{"slack", "pagerduty"}, SlackChannel: "#ops"}
+	if err := generateAlerts(dir, d, false, false); err != nil {
+		t.Fatalf("generateAlerts error: %v", err)
+	}
+	mon := filepath.Join(dir, "monitoring")
+	if _, err := os.Stat(filepath.Join(mon, "rules", "alerts.yml")); err != nil {
+		t.Error("monitoring/rules/alerts.yml not created")
+	}
+	scrape := readGenerated(t, mon, "prometheus.yml")
+	assertContains(t, "prometheus.yml", scrape, "/etc/prometheus/rules/*.yml", "alertmanager:9093")
+	raw := readGenerated(t, mon, "alertmanager.yml")
+	var am map[string]interface{}
+	if err := yaml.Unmarshal(raw, &am); err != nil {
+		t.Fatalf("alertmanager.yml is not valid YAML: %v", err)
+	}
+	for _, want := range []string{"api_url_file: /run/secrets/SLACK_WEBHOOK_URL", "channel: '#ops'",
+		"routing_key_file: /run/secrets/PAGERDUTY_ROUTING_KEY", `severity="critical"`} {
+		if !bytes.Contains(raw, []byte(want)) {
+			t.Errorf("alertmanager.yml missing %q", want)
+		}
+	}
+
+	d.Alerts = config.AlertsConfig{Receivers: []string{"email"}}
+	if err := generateAlerts(t.TempDir(), d, false, false); err == nil {
+		t.Error("expected email without email_to and smtp_host to be rejected")
+	}
+}
+
+func TestGenerateDB_Standalone(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.DB = "redis"
+	if err := generateDB(dir, d, false, false); err != nil {
+		t.Fatalf("generateDB error: %v", err)
+	}
+	content := readGenerated(t, dir, "docker-compose.redis.yml")
+	assertContains(t, "docker-compose.redis.yml", content, "name: testapp-redis", "redis-cli", "name: testapp-backend")
+	if bytes.Contains(content, []byte("profiles:")) {
+		t.Error("standalone compose files should not use profiles")
+	}
+}
+
+// ─── Kubernetes ──────────────────────────────────────────────────────────────
+
+func TestGenerateK8s_Kustomize(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.Environments = []string{"dev", "prod"}
+	if err := generateK8s(dir, d, "kustomize", false, false); err != nil {
+		t.Fatalf("generateK8s (kustomize) error: %v", err)
+	}
+	for _, f := range []string{
+		filepath.Join("k8s", "base", "kustomization.yaml"),
+		filepath.Join("k8s", "base", "deployment.yaml"),
+		filepath.Join("k8s", "overlays", "dev", "kustomization.yaml"),
+		filepath.Join("k8s", "overlays", "prod", "deployment-patch.yaml"),
+	} {
+		if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+			t.Errorf("%s not created", f)
+		}
+	}
+	content := readGenerated(t, dir, "k8s", "overlays", "prod", "kustomization.yaml")
+	if !bytes.Contains(content, []byte("value: testapp.example.com")) {
+		t.Error("expected prod overlay to patch the ingress host")
+	}
+	if !bytes.Contains(content, []byte("- name: ghcr.io/testapp/testapp")) {
+		t.Error("expected prod overlay to retag the registry image")
+	}
+	if !bytes.Contains(content, []byte(`newTag: "latest"`)) {
+		t.Error("expected prod overlay to pin a tag CI pushes")
+	}
+}
+
+func TestGenerateK8s_UnknownFormat(t *testing.T) {
+	if err := generateK8s(t.TempDir(), testData(), "jsonnet", false, false); err == nil {
+		t.Error("expected error for unknown k8s format")
+	}
+}
+
+func TestGenerateK8s_CanaryArgoRollouts(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.Strategy = "canary"
+	if err := generateK8s(dir, d, "kustomize", false, false); err != nil {
+		t.Fatalf("generateK8s (canary) error: %v", err)
+	}
+	var rollout struct {
+		Kind string
+		Spec struct {
+			WorkloadRef struct{ Kind, Name string } `yaml:"workloadRef"`
+			Strategy    struct {
+				Canary struct {
+					Analysis struct {
+						Templates []struct {
+							TemplateName string `yaml:"templateName"`
+						}
+					}
+					Steps []map[string]interface{}
+				}
+			}
+		}
+	}
+	if err := yaml.Unmarshal(readGenerated(t, dir, "k8s", "base", "rollout.yaml"), &rollout); err != nil {
+		t.Fatalf("rollout.yaml is not valid YAML: %v", err)
+	}
+	if rollout.Kind != "Rollout" || rollout.Spec.WorkloadRef.Kind != "Deployment" || rollout.Spec.WorkloadRef.Name != "testapp" {
+		t.Errorf("expected a Rollout of the testapp Deployment, got %+v", rollout)
+	}
+	canary := rollout.Spec.Strategy.Canary
+	if len(canary.Analysis.Templates) != 1 || canary.Analysis.Templates[0].TemplateName != "testapp-analysis" {
+		t.Errorf("expected the rollout to run testapp-analysis, got %+v", canary.Analysis.Templates)
+	}
+	if len(canary.Steps) == 0 {
+		t.Error("expected canary steps")
+	}
+	var kust struct{ Resources []string }
+	if err := yaml.Unmarshal(readGenerated(t, dir, "k8s", "base", "kustomization.yaml"), &kust); err != nil {
+		t.Fatalf("kustomization.yaml is not valid YAML: %v", err)
+	}
+	listed := false
+	for _, r := range kust.Resources {
+		listed = listed || r == "analysistemplate.yaml"
+	}
+	if !listed {
+		t.Errorf("expected base kustomization to list analysistemplate.yaml, got %v", kust.Resources)
+	}
+}
+
+func TestGenerateK8s_BlueGreenFlagger(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.Strategy, d.Rollouts = "blue-green", "flagger"
+	if err := generateK8s(dir, d, "manifests", false, false); err != nil {
+		t.Fatalf("generateK8s (blue-green) error: %v", err)
+	}
+	canary, err := os.ReadFile(filepath.Join(dir, "k8s", "canary.yaml"))
+	if err != nil {
+		t.Fatal("canary.yaml not created")
+	}
+	if !bytes.Contains(canary, []byte("provider: kubernetes")) {
+		t.Error("expected blue-green Canary to use the kubernetes provider")
+	}
+	metrics := readGenerated(t, dir, "k8s", "metrictemplate.yaml")
+	if !bytes.Contains(metrics, []byte("[{{ interval }}]")) {
+		t.Error("expected MetricTemplate to keep Flagger's interval placeholder")
+	}
+}
+
+func TestGenerateK8s_UnknownStrategy(t *testing.T) {
+	d := testData()
+	d.Strategy = "shadow"
+	if err := generateK8s(t.TempDir(), d, "manifests", false, false); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+}
+
+func TestGenerateK8s_RolloutOwnsReplicas(t *testing.T) {
+	type workload struct {
+		Kind string `yaml:"kind"`
+		Spec struct {
+			Replicas    *int `yaml:"replicas"`
+			WorkloadRef struct {
+				Kind string `yaml:"kind"`
+			} `yaml:"workloadRef"`
+		} `yaml:"spec"`
+	}
+	read := func(path string) workload {
+		t.Helper()
+		var w workload
+		raw, _ := os.ReadFile(path)
+		if err := yaml.Unmarshal(raw, &w); err != nil {
+			t.Fatalf("%s is not valid YAML: %v", path, err)
+		}
+		return w
+	}
+
+	dir := t.TempDir()
+	d := testData()
+	d.Strategy, d.Environments = "canary", []string{"dev", "prod"}
+	if err := generateK8s(dir, d, "manifests", false, false); err != nil {
+		t.Fatalf("generateK8s error: %v", err)
+	}
+	if rollout := read(filepath.Join(dir, "k8s", "rollout.yaml")); rollout.Spec.WorkloadRef.Kind != "Deployment" {
+		t.Fatalf("rollout.yaml workloadRef = %+v", rollout.Spec.WorkloadRef)
+	}
+	if r := read(filepath.Join(dir, "k8s", "deployment.yaml")).Spec.Replicas; r == nil || *r != 0 {
+		t.Errorf("Deployment replicas = %v, want 0 under the Rollout", r)
+	}
+
+	dir = t.TempDir()
+	if err := generateK8s(dir, d, "kustomize", false, false); err != nil {
+		t.Fatalf("generateK8s error: %v", err)
+	}
+	if r := read(filepath.Join(dir, "k8s", "overlays", "prod", "deployment-patch.yaml")).Spec.Replicas; r != nil {
+		t.Errorf("overlay scales the Deployment to %d", *r)
+	}
+	overlay := readGenerated(t, dir, "k8s", "overlays", "prod", "kustomization.yaml")
+	if !bytes.Contains(overlay, []byte("kind: Rollout")) {
+		t.Error("overlay should size the Rollout instead")
+	}
+
+	dir = t.TempDir()
+	if err := generateHelm(dir, d, false, false, false); err != nil {
+		t.Fatalf("generateHelm error: %v", err)
+	}
+	deploy := readGenerated(t, dir, "charts", "testapp", "templates", "deployment.yaml")
+	if !bytes.Contains(deploy, []byte("replicas: 0")) || bytes.Contains(deploy, []byte(".Values.replicaCount")) {
+		t.Error("chart Deployment should stay at zero replicas under the Rollout")
+	}
+
+	d.Strategy = "rolling"
+	dir = t.TempDir()
+	if err := generateK8s(dir, d, "manifests", false, false); err != nil {
+		t.Fatalf("generateK8s error: %v", err)
+	}
+	if r := read(filepath.Join(dir, "k8s", "deployment.yaml")).Spec.Replicas; r == nil || *r != 2 {
+		t.Errorf("rolling Deployment replicas = %v, want 2", r)
+	}
+}
+
+func TestGenerateK8s_ServiceAccount(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.Registry, d.Environments = "123456789012.dkr.ecr.us-east-1.amazonaws.com", []string{"dev", "prod"}
+	if err := generateK8s(dir, d, "kustomize", false, false); err != nil {
+		t.Fatalf("generateK8s error: %v", err)
+	}
+	sa, err := os.ReadFile(filepath.Join(dir, "k8s", "base", "serviceaccount.yaml"))
+	if err != nil {
+		t.Fatal("serviceaccount.yaml not created")
+	}
+	if !bytes.Contains(sa, []byte(`eks.amazonaws.com/role-arn: "arn:aws:iam::123456789012:role/testapp-prod"`)) {
+		t.Errorf("expected the IRSA annotation for prod, got:\n%s", sa)
+	}
+	deploy := readGenerated(t, dir, "k8s", "base", "deployment.yaml")
+	if !bytes.Contains(deploy, []byte("serviceAccountName: testapp")) {
+		t.Error("expected the deployment to run as the app's ServiceAccount")
+	}
+	overlay := readGenerated(t, dir, "k8s", "overlays", "dev", "kustomization.yaml")
+	if !bytes.Contains(overlay, []byte("role/testapp-dev")) {
+		t.Error("expected the dev overlay to patch the role ARN")
+	}
+}
+
+func TestGenerateK8s_DomainTLS(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.Domain, d.ACMEEmail, d.Environments = "acme.io", "ops@acme.io", []string{"dev"}
+	if err := generateK8s(dir, d, "kustomize", false, false); err != nil {
+		t.Fatalf("generateK8s (domain) error: %v", err)
+	}
+	issuer, err := os.ReadFile(filepath.Join(dir, "k8s", "base", "clusterissuer.yaml"))
+	if err != nil {
+		t.Fatal("clusterissuer.yaml not created")
+	}
+	if !bytes.Contains(issuer, []byte("email: ops@acme.io")) {
+		t.Error("expected the ClusterIssuer to use the ACME email")
+	}
+	ingress := readGenerated(t, dir, "k8s", "base", "ingress.yaml")
+	if !bytes.Contains(ingress, []byte("secretName: testapp-tls")) {
+		t.Error("expected the ingress to terminate TLS with the certificate secret")
+	}
+	overlay := readGenerated(t, dir, "k8s", "overlays", "dev", "kustomization.yaml")
+	if n := bytes.Count(overlay, []byte("value: testapp.dev.acme.io")); n != 3 {
+		t.Errorf("expected the dev overlay to patch the rule, TLS and certificate hosts, got %d patches", n)
+	}
+}
+
+// ─── Terraform ───────────────────────────────────────────────────────────────
+
+func TestGenerateInfra_Inputs(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.Region, d.AZCount, d.NodeSize = "eu-west-1", 3, "m6i.large"
+	if err := generateInfra(dir, d, false, false); err != nil {
+		t.Fatalf("generateInfra error: %v", err)
+	}
+	infra := filepath.Join(dir, "infra", "aws")
+	main := readGenerated(t, infra, "main.tf")
+	if bytes.Contains(main, []byte(`"var.region`)) {
+		t.Error("main.tf still quotes variable references as AZ names")
+	}
+	tfvars := readGenerated(t, infra, "terraform.tfvars")
+	assertContains(t, "terraform.tfvars", tfvars, `region = "eu-west-1"`, "az_count           = 3", `node_instance_type = "m6i.large"`, `cluster_version    = "1.30"`)
+	outputs := readGenerated(t, infra, "outputs.tf")
+	assertContains(t, "outputs.tf", outputs, `output "cluster_endpoint"`, `output "registry_url"`, "aws eks update-kubeconfig")
+}
+
+func TestGenerateInfra_Registry(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.Compute = "ecs-fargate"
+	if err := generateInfra(dir, d, false, false); err != nil {
+		t.Fatalf("generateInfra error: %v", err)
+	}
+	infra := filepath.Join(dir, "infra", "aws")
+	registry, err := os.ReadFile(filepath.Join(infra, "registry.tf"))
+	if err != nil {
+		t.Fatal("registry.tf not created")
+	}
+	if !bytes.Contains(registry, []byte(`resource "aws_ecr_lifecycle_policy" "app"`)) {
+		t.Error("expected an ECR lifecycle policy")
+	}
+	for _, want := range []string{`data "aws_ecr_repository" "app"`, "count                = local.owns_registry ? 1 : 0"} {
+		if !bytes.Contains(registry, []byte(want)) {
+			t.Errorf("registry.tf missing %q: one environment creates the repository", want)
+		}
+	}
+	vars := readGenerated(t, infra, "variables.tf")
+	if !bytes.Contains(vars, []byte(`default     = "prod"`)) {
+		t.Error("expected the prod environment to own the registry")
+	}
+	main := readGenerated(t, infra, "main.tf")
+	if !bytes.Contains(main, []byte("image        = local.image")) {
+		t.Error("expected the task definition to use the resolved image")
+	}
+}
+
+func TestGenerateInfra_S3Backend(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.StateBackend = "s3"
+	d.Environments = []string{"dev", "prod"}
+	if err := generateInfra(dir, d, false, false); err != nil {
+		t.Fatalf("generateInfra error: %v", err)
+	}
+	infra := filepath.Join(dir, "infra", "aws")
+	for _, f := range []string{"backend.tf", filepath.Join("bootstrap", "main.tf"), filepath.Join("envs", "prod", "terraform.tfvars")} {
+		if _, err := os.Stat(filepath.Join(infra, f)); err != nil {
+			t.Errorf("%s not created", f)
+		}
+	}
+	hcl := readGenerated(t, infra, "envs", "dev", "backend.hcl")
+	if !bytes.Contains(hcl, []byte(`key = "testapp/dev/terraform.tfstate"`)) {
+		t.Errorf("unexpected dev backend.hcl: %s", hcl)
+	}
+}
+
+func TestGenerateInfra_Workspaces(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.StateBackend, d.StateLayout = "s3", "workspaces"
+	if err := generateInfra(dir, d, false, false); err != nil {
+		t.Fatalf("generateInfra error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "infra", "aws", "envs")); err == nil {
+		t.Error("workspaces layout should not create envs/")
+	}
+}
+
+func TestGenerateInfra_PerEnvClusterNames(t *testing.T) {
+	cases := map[string][]string{
+		"aws":   {`"testapp-${var.environment}-cluster"`},
+		"gcp":   {`"testapp-${var.environment}-cluster"`},
+		"azure": {`"testapp-${var.environment}-rg"`, `"testapp-${var.environment}-aks"`},
+	}
+	for provider, wants := range cases {
+		dir := t.TempDir()
+		d := testData()
+		d.Provider = provider
+		if err := generateInfra(dir, d, false, false); err != nil {
+			t.Fatalf("generateInfra (%s) error: %v", provider, err)
+		}
+		main := readGenerated(t, dir, "infra", provider, "main.tf")
+		for _, want := range wants {
+			if !bytes.Contains(main, []byte(want)) {
+				t.Errorf("%s main.tf missing %s", provider, want)
+			}
+		}
+	}
+}
+
+func TestGenerateInfra_BackendMismatch(t *testing.T) {
+	d := testData()
+	d.StateBackend = "gcs"
+	if err := generateInfra(t.TempDir(), d, false, false); err == nil {
+		t.Error("expected error for gcs backend on aws")
+	}
+}
+
+func TestGenerateInfra_Compute(t *testing.T) {
+	cases := []struct {
+		provider, compute, want string
+	}{
+		{"aws", "ecs-fargate", `resource "aws_ecs_service" "app"`},
+		{"aws", "lambda-container", `package_type  = "Image"`},
+		{"gcp", "cloud-run", `resource "google_cloud_run_v2_service" "app"`},
+		{"azure", "container-apps", `resource "azurerm_container_app" "app"`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.compute, func(t *testing.T) {
+			dir := t.TempDir()
+			d := testData()
+			d.Provider, d.Compute = tc.provider, tc.compute
+			if err := generateInfra(dir, d, false, false); err != nil {
+				t.Fatalf("generateInfra error: %v", err)
+			}
+			main := readGenerated(t, dir, "infra", tc.provider, "main.tf")
+			if !bytes.Contains(main, []byte(tc.want)) {
+				t.Errorf("main.tf missing %q", tc.want)
+			}
+			vars := readGenerated(t, dir, "infra", tc.provider, "variables.tf")
+			if !bytes.Contains(vars, []byte(`default     = "ghcr.io/testapp/testapp:latest"`)) {
+				t.Error("expected image variable to default to the registry image")
+			}
+		})
+	}
+}
+
+func TestGenerateInfra_ComputeWrongProvider(t *testing.T) {
+	d := testData()
+	d.Compute = "cloud-run"
+	if err := generateInfra(t.TempDir(), d, false, false); err == nil {
+		t.Error("expected error for cloud-run on aws")
+	}
+}
+
+func TestGenerateInfra_Database(t *testing.T) {
+	cases := []struct {
+		provider, compute, db, want string
+	}{
+		{"aws", "kubernetes", "postgres", `resource "aws_db_instance" "db"`},
+		{"aws", "ecs-fargate", "redis", `resource "aws_elasticache_replication_group" "db"`},
+		{"aws", "app-runner", "mongo", `resource "aws_docdb_cluster" "db"`},
+		{"gcp", "cloud-run", "mysql", `database_version    = "MYSQL_8_0"`},
+		{"gcp", "kubernetes", "redis", `resource "google_redis_instance" "db"`},
+		{"gcp", "kubernetes", "mongo", `resource "mongodbatlas_network_peering" "db"`},
+		{"azure", "container-apps", "postgres", `resource "azurerm_postgresql_flexible_server" "db"`},
+		{"azure", "kubernetes", "redis", `resource "azurerm_redis_cache" "db"`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.provider+"-"+tc.db, func(t *testing.T) {
+			dir := t.TempDir()
+			d := testData()
+			d.Provider, d.Compute, d.DB = tc.provider, tc.compute, tc.db
+			if err := generateInfra(dir, d, false, false); err != nil {
+				t.Fatalf("generateInfra error: %v", err)
+			}
+			infra := filepath.Join(dir, "infra", tc.provider)
+			db, err := os.ReadFile(filepath.Join(infra, "database.tf"))
+			if err != nil {
+				t.Fatal("database.tf not created")
+			}
+			if !bytes.Contains(db, []byte(tc.want)) {
+				t.Errorf("database.tf missing %q", tc.want)
+			}
+			if !bytes.Contains(db, []byte("_PASSWORD = ")) {
+				t.Error("expected db_secrets to reference the stored password")
+			}
+			outputs := readGenerated(t, infra, "outputs.tf")
+			if !bytes.Contains(outputs, []byte(`output "db_env"`)) {
+				t.Error("outputs.tf missing db_env")
+			}
+			if tc.compute != "kubernetes" {
+				main := readGenerated(t, infra, "main.tf")
+				if !bytes.Contains(main, []byte("local.db_env, var.env")) {
+					t.Error("expected the app env to include db_env")
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateInfra_NoDatabase(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.DB = "none"
+	if err := generateInfra(dir, d, false, false); err != nil {
+		t.Fatalf("generateInfra error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "infra", "aws", "database.tf")); err == nil {
+		t.Error("database.tf should not be generated without a db")
+	}
+}
+
+func TestGenerateInfra_Domain(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.Domain, d.Environments = "acme.io", []string{"dev", "prod"}
+	if err := generateInfra(dir, d, false, false); err != nil {
+		t.Fatalf("generateInfra error: %v", err)
+	}
+	infra := filepath.Join(dir, "infra", "aws")
+	dns, err := os.ReadFile(filepath.Join(infra, "dns.tf"))
+	if err != nil {
+		t.Fatal("dns.tf not created")
+	}
+	assertContains(t, "dns.tf", dns, `resource "aws_route53_zone" "main"`, `resource "helm_release" "ingress_nginx"`, `resource "helm_release" "cert_manager"`)
+	provider := readGenerated(t, infra, "provider.tf")
+	if !bytes.Contains(provider, []byte(`provider "helm"`)) {
+		t.Error("expected a helm provider for the ingress controller")
+	}
+}
+
+func TestGenerateInfra_WorkloadIdentity(t *testing.T) {
+	tests := []struct {
+		provider string
+		want     []string
+	}{
+		{"aws", []string{`resource "aws_iam_role" "app"`, "module.eks.oidc_provider_arn", "values(local.db_secrets)"}},
+		{"gcp", []string{`resource "google_service_account" "app"`, "roles/iam.workloadIdentityUser", "google_secret_manager_secret.db_password.id"}},
+		{"azure", []string{`resource "azurerm_federated_identity_credential" "app"`, "oidc_issuer_url", `resource "azurerm_role_assignment" "app_kv_reader"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			dir := t.TempDir()
+			d := testData()
+			d.Provider = tt.provider
+			if err := generateInfra(dir, d, false, false); err != nil {
+				t.Fatalf("generateInfra error: %v", err)
+			}
+			identity, err := os.ReadFile(filepath.Join(dir, "infra", tt.provider, "identity.tf"))
+			if err != nil {
+				t.Fatal("identity.tf not created")
+			}
+			assertContains(t, "identity.tf", identity, tt.want...)
+		})
+	}
+
+	dir := t.TempDir()
+	d := testData()
+	d.Compute = "ecs-fargate"
+	if err := generateInfra(dir, d, false, false); err != nil {
+		t.Fatalf("generateInfra (ecs-fargate) error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "infra", "aws", "identity.tf")); err == nil {
+		t.Error("identity.tf should only be generated for Kubernetes compute")
+	}
+}
+
+func TestGenerateInfra_CIIdentity(t *testing.T) {
+	tests := []struct {
+		provider, ci string
+		want         []string
+	}{
+		{"aws", "github-actions", []string{`resource "aws_iam_openid_connect_provider" "ci"`, "repo:${var.ci_repository}:environment:${var.environment}", `resource "aws_iam_role" "ci_image"`, `"ecr:PutImage"`}},
+		{"gcp", "gitlab-ci", []string{`resource "google_iam_workload_identity_pool_provider" "ci"`, `issuer_uri = "https://gitlab.com"`, "attribute.environment/${var.environment}", `role       = "roles/artifactregistry.writer"`}},
+		{"azure", "github-actions", []string{`resource "azurerm_federated_identity_credential" "ci_environment"`, "token.actions.githubusercontent.com", `role_definition_name = "AcrPush"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			dir := t.TempDir()
+			d := testData()
+			d.Provider, d.CI = tt.provider, tt.ci
+			if err := generateInfra(dir, d, false, false); err != nil {
+				t.Fatalf("generateInfra error: %v", err)
+			}
+			ci, err := os.ReadFile(filepath.Join(dir, "infra", tt.provider, "ci.tf"))
+			if err != nil {
+				t.Fatal("ci.tf not created")
+			}
+			assertContains(t, "ci.tf", ci, tt.want...)
+			// The default branch only gets the push-only image identity; the
+			// admin identity is for jobs bound to the environment.
+			if tt.ci == "github-actions" && bytes.Count(ci, []byte("ref:refs/heads/main")) != 1 {
+				t.Error("expected only the image identity to trust the default branch")
+			}
+			if tt.ci == "github-actions" && !bytes.Contains(ci, []byte("environment:release")) {
+				t.Error("expected the image identity to trust the release environment")
+			}
+			vars := readGenerated(t, dir, "infra", tt.provider, "variables.tf")
+			if !bytes.Contains(vars, []byte(`default     = "your-org/testapp"`)) {
+				t.Error("expected a placeholder ci_repository without a git remote")
+			}
+		})
+	}
+}
+
+func TestGenerateInfra_CIDeployScoped(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.Provider, d.CI, d.StateBackend = "aws", "github-actions", "s3"
+	d.Environments = []string{"dev", "prod"}
+	if err := generateInfra(dir, d, false, false); err != nil {
+		t.Fatalf("generateInfra error: %v", err)
+	}
+	ci := readGenerated(t, dir, "infra", "aws", "ci.tf")
+	for _, want := range []string{
+		`var.ci_admin ? "arn:aws:iam::aws:policy/AdministratorAccess" : "arn:aws:iam::aws:policy/ReadOnlyAccess"`,
+		`"arn:aws:s3:::testapp-tfstate/testapp/${var.environment}/*"`,
+		`table/testapp-tflock"`,
+		`resource "kubernetes_role_binding" "ci"`,
+	} {
+		if !bytes.Contains(ci, []byte(want)) {
+			t.Errorf("ci.tf missing %s", want)
+		}
+	}
+	main := readGenerated(t, dir, "infra", "aws", "main.tf")
+	if !bytes.Contains(main, []byte(`groups   = var.ci_admin ? ["system:masters"] : ["testapp-ci"]`)) {
+		t.Error("CI should only get system:masters with ci_admin")
+	}
+}
+
+func TestGenerateInfra_ApplyOrder(t *testing.T) {
+	d := testData()
+	d.Environments = []string{"dev", "prod"}
+	out := captureStdout(t, func() {
+		if err := generateInfra(t.TempDir(), d, false, false); err != nil {
+			t.Fatalf("generateInfra error: %v", err)
+		}
+	})
+	if !strings.Contains(out, "apply the prod environment first: it creates the registry the others") {
+		t.Errorf("expected the apply order in:\n%s", out)
+	}
+
+	d.Domain = "example.com"
+	out = captureStdout(t, func() {
+		if err := generateInfra(t.TempDir(), d, false, false); err != nil {
+			t.Fatalf("generateInfra error: %v", err)
+		}
+	})
+	if !strings.Contains(out, "it creates the registry and DNS zone the others look up") {
+		t.Errorf("expected the DNS zone in the apply order:\n%s", out)
+	}
+}
+
+func TestAzureStorageAccountName(t *testing.T) {
+	if got := azureStorageAccountName("My-Really-Long-Service-Name"); got != "myreallylongservitfstate" {
+		t.Errorf("azureStorageAccountName = %q", got)
+	}
+}
+
+// ─── CI ──────────────────────────────────────────────────────────────────────
+
+func TestGenerateCI_PushECR(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.CI = "github-actions"
+	d.Registry = "123456789012.dkr.ecr.us-east-1.amazonaws.com"
+	if err := generateCI(dir, d, false, false, false); err != nil {
+		t.Fatalf("generateCI error: %v", err)
+	}
+	content := readGenerated(t, dir, ".github", "workflows", "ci.yml")
+	assertContains(t, "workflow", content,
+		"id-token: write",
+		"aws-actions/amazon-ecr-login@v2",
+		"IMAGE: 123456789012.dkr.ecr.us-east-1.amazonaws.com/testapp",
+		"${{ env.IMAGE }}:${{ github.sha }}",
+		"role-to-assume: ${{ vars.AWS_IMAGE_ROLE_ARN }}",
+	)
+}
+
+func TestGenerateCI_NoRegistry(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.CI, d.Registry = "gitlab-ci", ""
+	if err := generateCI(dir, d, false, false, false); err != nil {
+		t.Fatalf("generateCI error: %v", err)
+	}
+	content := readGenerated(t, dir, ".gitlab-ci.yml")
+	if bytes.Contains(content, []byte("publish")) {
+		t.Error("expected no publish stage without a registry")
+	}
+}
+
+func TestGenerateCI_Deploy(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.CI, d.StateBackend, d.Environments = "github-actions", "s3", []string{"dev", "prod"}
+	if err := generateCI(dir, d, true, false, false); err == nil {
+		t.Fatal("expected an error before any chart or manifests exist")
+	}
+	if err := generateHelm(dir, d, false, false, false); err != nil {
+		t.Fatalf("generateHelm error: %v", err)
+	}
+	if err := generateCI(dir, d, true, false, false); err != nil {
+		t.Fatalf("generateCI (deploy) error: %v", err)
+	}
+	content := readGenerated(t, dir, ".github", "workflows", "deploy.yml")
+	assertContains(t, "workflow", content,
+		"workflows: [ \"CI\" ]",
+		"deploy-dev:\n    if: github.event.workflow_run.conclusion == 'success'",
+		"deploy-prod:\n    needs: deploy-dev",
+		"environment: prod",
+		"role-to-assume: ${{ vars.AWS_ROLE_ARN }}",
+		"-backend-config=envs/dev/backend.hcl",
+		"helm upgrade --install testapp charts/testapp --namespace testapp-prod",
+	)
+}
+
+func TestGenerateCI_DeployServerless(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.CI, d.Provider, d.Compute = "gitlab-ci", "gcp", "cloud-run"
+	d.Registry, d.StateBackend, d.StateLayout = "us-docker.pkg.dev/acme/apps", "gcs", "workspaces"
+	d.Environments = []string{"dev", "prod"}
+	if err := generateCI(dir, d, true, false, false); err != nil {
+		t.Fatalf("generateCI (deploy) error: %v", err)
+	}
+	content := readGenerated(t, dir, ".gitlab-ci.yml")
+	assertContains(t, ".gitlab-ci.yml", content,
+		"  - deploy\n",
+		"extends: .deploy",
+		`terraform workspace select -or-create "$DEPLOY_ENV"`,
+		`-var image="$IMAGE:$CI_COMMIT_SHA"`,
+	)
+	if bytes.Contains(content, []byte("kubectl")) {
+		t.Error("Cloud Run deploys should not touch a cluster")
+	}
+
+	d.StateBackend = ""
+	if err := generateCI(t.TempDir(), d, true, false, false); err == nil {
+		t.Error("expected an error without a remote state backend")
+	}
+}
+
+func TestGenerateCI_Composed(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.CI, d.Language, d.Registry = "github-actions", "node", ""
+	d.CIVersions = []string{"18", "20"}
+	for _, f := range []string{"pnpm-lock.yaml", "Dockerfile", filepath.Join("charts", "testapp", "Chart.yaml"), filepath.Join("infra", "aws", "main.tf")} {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(f)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, f), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := generateCI(dir, d, false, false, false); err != nil {
+		t.Fatalf("generateCI error: %v", err)
+	}
+	content := readGenerated(t, dir, ".github", "workflows", "ci.yml")
+	assertContains(t, "workflow", content,
+		"version: ['18', '20']",
+		"if: matrix.version == '18'",
+		"cache: pnpm",
+		"pnpm exec eslint .",
+		"helm lint charts/testapp",
+		"terraform init -input=false -backend=false",
+		"cache-from: type=gha",
+		"anchore/sbom-action",
+		"aquasecurity/trivy-action",
+	)
+	if bytes.Contains(content, []byte("docker push")) {
+		t.Error("expected no push without a registry")
+	}
+	security := readGenerated(t, dir, ".github", "workflows", "security.yml")
+	assertContains(t, "security.yml", security, "exo scan --fail", "scan-type: fs", "scan-ref: infra/aws")
+
+	// Nothing to lint, plan or build beyond the code itself.
+	bare := t.TempDir()
+	if err := generateCI(bare, d, false, false, false); err != nil {
+		t.Fatalf("generateCI error: %v", err)
+	}
+	content = readGenerated(t, bare, ".github", "workflows", "ci.yml")
+	var workflow struct{ Jobs map[string]interface{} }
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		t.Fatalf("ci.yml is not valid YAML: %v", err)
+	}
+	for job := range workflow.Jobs {
+		if job != "build" {
+			t.Errorf("unexpected %s job", job)
+		}
+	}
+	if !bytes.Contains(content, []byte("cache: npm")) {
+		t.Error("expected npm caching without a pnpm lockfile")
+	}
+}
+
+func TestGenerateCI_GitLabComposed(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.CI, d.Language = "gitlab-ci", "python"
+	d.CIVersions = []string{"3.11", "3.12"}
+	for _, f := range []string{"poetry.lock", "Dockerfile", filepath.Join("charts", "testapp", "Chart.yaml"), filepath.Join("infra", "aws", "main.tf")} {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(f)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, f), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := generateCI(dir, d, false, false, false); err != nil {
+		t.Fatalf("generateCI error: %v", err)
+	}
+	raw := readGenerated(t, dir, ".gitlab-ci.yml")
+	type job struct {
+		Stage    string
+		Image    any
+		Extends  string
+		Script   []string
+		Coverage string
+		Parallel struct {
+			Matrix []map[string][]string
+		}
+		Cache struct {
+			Key   any
+			Paths []string
+		}
+		Variables map[string]string
+	}
+	var nodes map[string]yaml.Node
+	if err := yaml.Unmarshal(raw, &nodes); err != nil {
+		t.Fatalf(".gitlab-ci.yml is not valid YAML: %v\n%s", err, raw)
+	}
+	pipeline := map[string]job{}
+	for name, n := range nodes {
+		if name == "stages" {
+			continue
+		}
+		var j job
+		if err := n.Decode(&j); err != nil {
+			t.Fatalf("job %s: %v", name, err)
+		}
+		pipeline[name] = j
+	}
+	for _, name := range []string{"lint", "test", "helm", "terraform", "secrets", "dependencies", "iac", "docker-image", "publish"} {
+		if _, ok := pipeline[name]; !ok {
+			t.Errorf("missing %s job", name)
+		}
+	}
+
+	test := pipeline["test"]
+	if test.Image != "python:${VERSION}-slim" || len(test.Parallel.Matrix) != 1 || strings.Join(test.Parallel.Matrix[0]["VERSION"], ",") != "3.11,3.12" {
+		t.Errorf("test job doesn't run the version matrix: image %v, matrix %v", test.Image, test.Parallel.Matrix)
+	}
+	if test.Extends != ".cache" || test.Coverage == "" {
+		t.Errorf("test job: extends %q, coverage %q", test.Extends, test.Coverage)
+	}
+	if cache := pipeline[".cache"]; cache.Variables["POETRY_CACHE_DIR"] == "" || !strings.Contains(fmt.Sprint(cache.Cache.Key), "poetry.lock") {
+		t.Errorf("cache isn't keyed on poetry: %+v", cache)
+	}
+	if lint := strings.Join(pipeline["lint"].Script, "\n"); !strings.Contains(lint, "ruff check .") {
+		t.Errorf("lint job doesn't run ruff:\n%s", lint)
+	}
+	if plan := strings.Join(pipeline["terraform"].Script, "\n"); !strings.Contains(plan, "terraform validate") {
+		t.Errorf("terraform job doesn't validate:\n%s", plan)
+	}
+	build := strings.Join(pipeline["docker-image"].Script, "\n")
+	for _, want := range []string{"--cache-to type=local", "syft ", "trivy image"} {
+		if !strings.Contains(build, want) {
+			t.Errorf("docker-image job missing %q", want)
+		}
+	}
+	if publish := strings.Join(pipeline["publish"].Script, "\n"); !strings.Contains(publish, "--cache-from type=local,src=.buildx-cache") {
+		t.Errorf("publish doesn't reuse the layer cache:\n%s", publish)
+	}
+}
+
+func TestGenerateCI_PinnedToolchain(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/testapp\n\ngo 1.23.4\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	d := testData()
+	d.CI = "github-actions"
+	if err := generateCI(dir, d, false, false, false); err != nil {
+		t.Fatalf("generateCI error: %v", err)
+	}
+	content := readGenerated(t, dir, ".github", "workflows", "ci.yml")
+	if !bytes.Contains(content, []byte("version: ['1.23']")) {
+		t.Errorf("expected the matrix to follow go.mod:\n%s", content)
+	}
+	if got := newCIData(dir, d).Toolchain.Image; got != "golang:1.23" {
+		t.Errorf("CI image = %q, want golang:1.23", got)
+	}
+}
+
+func TestGenerateCI_MigratesLegacyWorkflow(t *testing.T) {
+	dir := t.TempDir()
+	workflows := filepath.Join(dir, ".github", "workflows")
+	if err := os.MkdirAll(workflows, 0755); err != nil {
+		t.Fatal(err)
+	}
+	legacy := filepath.Join(workflows, "go.yml")
+	if err := os.WriteFile(legacy, []byte("name: Build and Test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	own := filepath.Join(workflows, "go-ci.yml")
+	if err := os.WriteFile(own, []byte("name: Nightly\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := testData()
+	d.CI = "github-actions"
+	if err := generateCI(dir, d, false, false, false); err != nil {
+		t.Fatalf("generateCI error: %v", err)
+	}
+	if fileExists(legacy) {
+		t.Error("expected go.yml to be renamed to ci.yml")
+	}
+	if !fileExists(own) {
+		t.Error("a workflow exo did not write must be left alone")
+	}
+	content := readGenerated(t, workflows, "ci.yml")
+	if !bytes.HasPrefix(content, []byte("name: CI\n")) {
+		t.Errorf("ci.yml was not regenerated:\n%s", content)
+	}
+}
+
+func TestCIPath(t *testing.T) {
+	tests := []struct{ ci, concern, want string }{
+		{"github-actions", "ci", filepath.Join(".github", "workflows", "ci.yml")},
+		{"github-actions", "release", filepath.Join(".github", "workflows", "release.yml")},
+		{"gitlab-ci", "deploy", ".gitlab-ci.yml"},
+		{"circleci", "security", filepath.Join(".circleci", "config.yml")},
+	}
+	for _, tt := range tests {
+		if got := ciPath(tt.ci, tt.concern); got != tt.want {
+			t.Errorf("ciPath(%q, %q) = %q, want %q", tt.ci, tt.concern, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateCI_OtherSystems(t *testing.T) {
+	cases := []struct {
+		ci, out string
+		want    []string
+	}{
+		{"jenkins", "Jenkinsfile", []string{"image 'golang:1.22'", "sh 'go vet ./...'", "stage('Image')"}},
+		{"circleci", filepath.Join(".circleci", "config.yml"), []string{"image: golang:1.22", "command: go test", "setup_remote_docker"}},
+		{"azure-pipelines", "azure-pipelines.yml", []string{"container: golang:1.22", "displayName: Lint", "stage: Image"}},
+		{"bitbucket-pipelines", "bitbucket-pipelines.yml", []string{"image: golang:1.22", "- go build -v ./...", "- step: *image"}},
+		{"woodpecker", ".woodpecker.yml", []string{"image: golang:1.22", "lint:", "from_secret: registry_password"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.ci, func(t *testing.T) {
+			dir := t.TempDir()
+			d := testData()
+			d.CI, d.Registry = tc.ci, "ghcr.io/acme"
+			if err := generateCI(dir, d, false, false, false); err != nil {
+				t.Fatalf("generateCI error: %v", err)
+			}
+			content, err := os.ReadFile(filepath.Join(dir, tc.out))
+			if err != nil {
+				t.Fatalf("%s not written: %v", tc.out, err)
+			}
+			assertContains(t, tc.out, content, tc.want...)
+			if !bytes.Contains(content, []byte("ghcr.io/acme/testapp")) {
+				t.Errorf("%s does not push the image", tc.out)
+			}
+			if err := generateCI(dir, d, true, false, true); err == nil {
+				t.Error("expected --deploy to be rejected")
+			}
+		})
+	}
+}
+
+// ─── Release ─────────────────────────────────────────────────────────────────
+
+func TestGenerateRelease(t *testing.T) {
+	cases := []struct {
+		lang, tool string
+		files      []string
+		want       string // expected in the tool's main config file
+	}{
+		{"go", "", []string{".goreleaser.yaml", "Dockerfile.goreleaser"}, "name: homebrew-tap"},
+		{"node", "", []string{".releaserc.json"}, "@semantic-release/github"},
+		{"node", "changesets", []string{filepath.Join(".changeset", "config.json")}, `"baseBranch": "main"`},
+		{"python", "", []string{"release-please-config.json", ".release-please-manifest.json"}, `"release-type": "python"`},
+		{"java", "", []string{"release-please-config.json"}, `"release-type": "maven"`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.lang+"/"+tc.tool, func(t *testing.T) {
+			dir := t.TempDir()
+			d := testData()
+			d.Language = tc.lang
+			if err := generateRelease(dir, d, tc.tool, false, false); err != nil {
+				t.Fatalf("generateRelease error: %v", err)
+			}
+			for _, f := range tc.files {
+				if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+					t.Errorf("%s not written", f)
+				}
+			}
+			content := readGenerated(t, dir, tc.files[0])
+			if !bytes.Contains(content, []byte(tc.want)) {
+				t.Errorf("%s missing %q", tc.files[0], tc.want)
+			}
+			wf, err := os.ReadFile(filepath.Join(dir, ".github", "workflows", "release.yml"))
+			if err != nil {
+				t.Fatalf("release.yml not written: %v", err)
+			}
+			if !bytes.Contains(wf, []byte(`tags: [ "v*" ]`)) {
+				t.Error("release.yml is not tag-triggered")
+			}
+			if !bytes.Contains(wf, []byte("ghcr.io/testapp")) && tc.lang != "go" {
+				t.Error("release.yml does not publish to the configured registry")
+			}
+		})
+	}
+}
+
+func TestGenerateRelease_GoImages(t *testing.T) {
+	dir := t.TempDir()
+	if err := generateRelease(dir, testData(), "", false, false); err != nil {
+		t.Fatalf("generateRelease error: %v", err)
+	}
+	content := readGenerated(t, dir, ".goreleaser.yaml")
+	assertContains(t, ".goreleaser.yaml", content, "CGO_ENABLED=0", "goarch: [amd64, arm64]", "ghcr.io/testapp/testapp:{{ .Version }}-arm64", "docker_manifests:", "name_template: checksums.txt")
+
+	d := testData()
+	d.Registry = ""
+	dir = t.TempDir()
+	if err := generateRelease(dir, d, "", false, false); err != nil {
+		t.Fatalf("generateRelease error: %v", err)
+	}
+	content = readGenerated(t, dir, ".goreleaser.yaml")
+	if bytes.Contains(content, []byte("dockers:")) {
+		t.Error("images configured without a registry")
+	}
+}
+
+func TestGenerateRelease_PublishEnvironment(t *testing.T) {
+	// Tag builds sign in to a cloud registry through the release environment,
+	// which the image identity in ci.tf trusts; ghcr needs no environment.
+	for registry, want := range map[string]bool{
+		"123456789012.dkr.ecr.us-east-1.amazonaws.com/testapp": true,
+		"ghcr.io/testapp": false,
+	} {
+		dir := t.TempDir()
+		d := testData()
+		d.Language, d.Registry = "node", registry
+		if err := generateRelease(dir, d, "", false, false); err != nil {
+			t.Fatalf("generateRelease error: %v", err)
+		}
+		wf := readGenerated(t, dir, ".github", "workflows", "release.yml")
+		if got := bytes.Contains(wf, []byte("environment: release")); got != want {
+			t.Errorf("%s: environment: release = %v, want %v", registry, got, want)
+		}
+	}
+}
+
+func TestGenerateRelease_Rejects(t *testing.T) {
+	d := testData()
+	if err := generateRelease(t.TempDir(), d, "semantic-release", false, false); err == nil {
+		t.Error("expected semantic-release to be rejected for Go")
+	}
+	d.Language = "python"
+	if err := generateRelease(t.TempDir(), d, "goreleaser", false, false); err == nil {
+		t.Error("expected goreleaser to be rejected for Python")
+	}
+	if err := generateRelease(t.TempDir(), d, "bogus", false, false); err == nil {
+		t.Error("expected unknown tool to be rejected")
+	}
+}
+
+func TestGenerateRelease_GitLab(t *testing.T) {
+	type job struct {
+		Stage string
+		Rules []struct{ If string }
+	}
+	readPipeline := func(t *testing.T, dir string) map[string]job {
+		t.Helper()
+		raw, err := os.ReadFile(filepath.Join(dir, ".gitlab-ci.yml"))
+		if err != nil {
+			t.Fatalf(".gitlab-ci.yml not written: %v", err)
+		}
+		var nodes map[string]yaml.Node
+		if err := yaml.Unmarshal(raw, &nodes); err != nil {
+			t.Fatalf(".gitlab-ci.yml is not valid YAML: %v", err)
+		}
+		jobs := map[string]job{}
+		for name, n := range nodes {
+			var j job
+			if n.Kind == yaml.MappingNode && n.Decode(&j) == nil {
+				jobs[name] = j
+			}
+		}
+		return jobs
+	}
+	onTags := func(j job) bool {
+		return len(j.Rules) == 1 && strings.HasPrefix(j.Rules[0].If, "$CI_COMMIT_TAG")
+	}
+
+	dir := t.TempDir()
+	d := testData()
+	d.CI = "gitlab-ci"
+	if err := generateRelease(dir, d, "", false, false); err != nil {
+		t.Fatalf("generateRelease error: %v", err)
+	}
+	jobs := readPipeline(t, dir)
+	for _, name := range []string{"goreleaser", "release-image"} {
+		if j, ok := jobs[name]; !ok || j.Stage != "release" || !onTags(j) {
+			t.Errorf("%s: want a tag-triggered release job, got %+v (present %v)", name, j, ok)
+		}
+	}
+	if fileExists(filepath.Join(dir, "Dockerfile.goreleaser")) {
+		t.Error("GitLab builds release images from the Dockerfile, not Dockerfile.goreleaser")
+	}
+	goreleaser := readGenerated(t, dir, ".goreleaser.yaml")
+	if bytes.Contains(goreleaser, []byte("dockers:")) || !bytes.Contains(goreleaser, []byte("homepage: https://gitlab.com/")) {
+		t.Errorf(".goreleaser.yaml is not set up for GitLab:\n%s", goreleaser)
+	}
+
+	// A pipeline exo gen ci already wrote is left alone; regenerating it
+	// picks up the configured tool.
+	dir = t.TempDir()
+	d.Language = "node"
+	if err := generateCI(dir, d, false, false, false); err != nil {
+		t.Fatalf("generateCI error: %v", err)
+	}
+	if err := generateRelease(dir, d, "", false, false); err != nil {
+		t.Fatalf("generateRelease error: %v", err)
+	}
+	if _, ok := readPipeline(t, dir)["semantic-release"]; ok {
+		t.Error("generateRelease overwrote .gitlab-ci.yml")
+	}
+	if err := generateCI(dir, d, false, false, true); err != nil {
+		t.Fatalf("generateCI error: %v", err)
+	}
+	if j, ok := readPipeline(t, dir)["semantic-release"]; !ok || onTags(j) {
+		t.Errorf("want semantic-release on the default branch, got %+v (present %v)", j, ok)
+	}
+	releaserc := readGenerated(t, dir, ".releaserc.json")
+	if !bytes.Contains(releaserc, []byte("@semantic-release/gitlab")) {
+		t.Error(".releaserc.json does not publish GitLab releases")
+	}
+
+	for name, c := range map[string]func(*config.TemplateData){
+		"release-please": func(d *config.TemplateData) { d.Language = "python" },
+		"acr":            func(d *config.TemplateData) { d.Registry = "testapp.azurecr.io" },
+		"circleci":       func(d *config.TemplateData) { d.CI = "circleci" },
+	} {
+		d := testData()
+		d.CI = "gitlab-ci"
+		c(&d)
+		if err := generateRelease(t.TempDir(), d, "", false, false); err == nil {
+			t.Errorf("%s: expected an error rather than a release nothing publishes", name)
+		}
+	}
+}
+
+// ─── GitOps ──────────────────────────────────────────────────────────────────
+
+func TestGenerateGitOps_NoSource(t *testing.T) {
+	if err := generateGitOps(t.TempDir(), testData(), "argocd", false, false); err == nil {
+		t.Error("expected error when neither charts/ nor k8s/ exist")
+	}
+}
+
+func TestGenerateGitOps_ArgoHelm(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.Environments = []string{"dev", "prod"}
+	if err := generateHelm(dir, d, false, false, false); err != nil {
+		t.Fatalf("generateHelm error: %v", err)
+	}
+	if err := generateGitOps(dir, d, "argocd", false, false); err != nil {
+		t.Fatalf("generateGitOps error: %v", err)
+	}
+	dev := readGenerated(t, dir, "gitops", "argocd", "dev.yaml")
+	if !bytes.Contains(dev, []byte("path: charts/testapp")) || !bytes.Contains(dev, []byte("selfHeal: true")) {
+		t.Errorf("dev Application should sync charts/testapp automatically:\n%s", dev)
+	}
+	prod := readGenerated(t, dir, "gitops", "argocd", "prod.yaml")
+	if bytes.Contains(prod, []byte("automated:")) {
+		t.Error("prod Application should not sync automatically")
+	}
+}
+
+func TestGenerateGitOps_FluxKustomize(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.Environments = []string{"staging"}
+	if err := generateK8s(dir, d, "kustomize", false, false); err != nil {
+		t.Fatalf("generateK8s error: %v", err)
+	}
+	if err := generateGitOps(dir, d, "flux", false, false); err != nil {
+		t.Fatalf("generateGitOps error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "gitops", "flux", "source.yaml")); err != nil {
+		t.Error("gitops/flux/source.yaml not created")
+	}
+	content := readGenerated(t, dir, "gitops", "flux", "staging.yaml")
+	if !bytes.Contains(content, []byte("path: ./k8s/overlays/staging")) {
+		t.Errorf("expected Flux Kustomization to point at the staging overlay:\n%s", content)
+	}
+}
+
+// ─── Helm ────────────────────────────────────────────────────────────────────
+
+func TestGenerateHelm(t *testing.T) {
+	dir := t.TempDir()
+	if err := generateHelm(dir, testData(), false, false, false); err != nil {
+		t.Fatalf("generateHelm error: %v", err)
+	}
+	chart := filepath.Join(dir, "charts", "testapp")
+	for _, f := range []string{
+		"Chart.yaml",
+		"values.yaml",
+		"values.schema.json",
+		filepath.Join("templates", "_helpers.tpl"),
+		filepath.Join("templates", "hpa.yaml"),
+		filepath.Join("templates", "serviceaccount.yaml"),
+		filepath.Join("templates", "NOTES.txt"),
+		filepath.Join("templates", "tests", "test-connection.yaml"),
+	} {
+		if _, err := os.Stat(filepath.Join(chart, f)); err != nil {
+			t.Errorf("charts/testapp/%s not created", f)
+		}
+	}
+	helpers := readGenerated(t, chart, "templates", "_helpers.tpl")
+	if !bytes.Contains(helpers, []byte(`{{- define "testapp.fullname" -}}`)) {
+		t.Error("expected _helpers.tpl to define testapp.fullname")
+	}
+}
+
+func TestHelmValuesSchema(t *testing.T) {
+	values := []byte("replicaCount: 2\nimage:\n  tag: latest\ningress:\n  enabled: true\npodAnnotations: {}\n")
+	out, err := helmValuesSchema(values)
+	if err != nil {
+		t.Fatalf("helmValuesSchema error: %v", err)
+	}
+	var schema struct {
+		Properties map[string]struct {
+			Type       string `json:"type"`
+			Properties map[string]struct {
+				Type string `json:"type"`
+			} `json:"properties"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(out, &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	cases := map[string]string{
+		"replicaCount":   "integer",
+		"image":          "object",
+		"podAnnotations": "object",
+	}
+	for key, want := range cases {
+		if got := schema.Properties[key].Type; got != want {
+			t.Errorf("%s type = %q, want %q", key, got, want)
+		}
+	}
+	if got := schema.Properties["ingress"].Properties["enabled"].Type; got != "boolean" {
+		t.Errorf("ingress.enabled type = %q, want boolean", got)
+	}
+}
+
+func TestHelmValuesSchema_Closed(t *testing.T) {
+	values := []byte("replicaCount: 2\nresources:\n  limits:\n    cpu: 500m\nenv:\n  APP_ENV: production\npostgresql:\n  auth:\n    username: admin\n")
+	out, err := helmValuesSchema(values, "postgresql")
+	if err != nil {
+		t.Fatalf("helmValuesSchema error: %v", err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(out, &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	prop := func(node map[string]interface{}, path ...string) map[string]interface{} {
+		for _, k := range path {
+			node, _ = node["properties"].(map[string]interface{})[k].(map[string]interface{})
+		}
+		return node
+	}
+	if schema["additionalProperties"] != false {
+		t.Error("root should reject unknown keys such as replicaCont")
+	}
+	if _, ok := prop(schema, "global")["type"]; !ok {
+		t.Error("root should accept Helm's global section")
+	}
+	for _, path := range [][]string{{"env"}, {"postgresql"}, {"postgresql", "auth"}, {"resources", "limits"}} {
+		if _, closed := prop(schema, path...)["additionalProperties"]; closed {
+			t.Errorf("%s should accept extra keys", strings.Join(path, "."))
+		}
+	}
+	if got := prop(schema, "resources")["additionalProperties"]; got != false {
+		t.Error("resources should reject unknown keys")
+	}
+	if got, _ := prop(schema, "resources", "limits", "cpu")["type"].([]interface{}); len(got) != 3 {
+		t.Errorf("resources.limits.cpu type = %v, want string, integer or number", got)
+	}
+}
+
+func TestGenerateHelm_WithDeps(t *testing.T) {
+	dir := t.TempDir()
+	if err := generateHelm(dir, testData(), true, false, false); err != nil {
+		t.Fatalf("generateHelm (with deps) error: %v", err)
+	}
+	chart := filepath.Join(dir, "charts", "testapp")
+	content := readGenerated(t, chart, "Chart.yaml")
+	assertContains(t, "Chart.yaml", content, "name: postgresql", "condition: postgresql.enabled", "name: kube-prometheus-stack")
+	values := readGenerated(t, chart, "values.yaml")
+	if !bytes.Contains(values, []byte("POSTGRES_HOST: testapp-postgresql")) {
+		t.Error("expected values.yaml env to point at the postgresql subchart")
+	}
+	if _, err := os.Stat(filepath.Join(chart, "templates", "servicemonitor.yaml")); err != nil {
+		t.Error("servicemonitor.yaml not created for prometheus monitoring")
+	}
+	for _, f := range []string{filepath.Join("templates", "prometheusrule.yaml"), filepath.Join("templates", "alertmanager-secret.yaml"), filepath.Join("rules", "alerts.yml")} {
+		if _, err := os.Stat(filepath.Join(chart, f)); err != nil {
+			t.Errorf("%s not created", f)
+		}
+	}
+	var v struct {
+		Stack struct {
+			Alertmanager struct {
+				Config struct {
+					Receivers []struct {
+						Name string `yaml:"name"`
+					} `yaml:"receivers"`
+				} `yaml:"config"`
+			} `yaml:"alertmanager"`
+		} `yaml:"kube-prometheus-stack"`
+	}
+	if err := yaml.Unmarshal(values, &v); err != nil {
+		t.Fatalf("values.yaml is not valid YAML: %v", err)
+	}
+	if len(v.Stack.Alertmanager.Config.Receivers) == 0 {
+		t.Error("expected kube-prometheus-stack.alertmanager.config to carry the routing")
+	}
+}
+
+func TestGenerateHelm_WithDepsObservability(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.Monitoring = config.MonitoringConfig{Metrics: "victoriametrics", Logs: "loki", Traces: "jaeger", Collector: true}
+	if err := generateHelm(dir, d, true, false, false); err != nil {
+		t.Fatalf("generateHelm (with deps) error: %v", err)
+	}
+	chart := filepath.Join(dir, "charts", "testapp")
+	content := readGenerated(t, chart, "Chart.yaml")
+	assertContains(t, "Chart.yaml", content, "name: victoria-metrics-k8s-stack", "name: loki", "name: promtail", "name: jaeger", "name: opentelemetry-collector")
+	values := readGenerated(t, chart, "values.yaml")
+	var v map[string]interface{}
+	if err := yaml.Unmarshal(values, &v); err != nil {
+		t.Fatalf("values.yaml is not valid YAML: %v", err)
+	}
+	assertContains(t, "values.yaml", values, "OTEL_EXPORTER_OTLP_ENDPOINT: http://testapp-otel-collector:4318", "url: http://testapp-loki:3100", "otlp/jaeger:")
+	scrape := readGenerated(t, chart, "templates", "servicemonitor.yaml")
+	if !bytes.Contains(scrape, []byte("kind: VMServiceScrape")) {
+		t.Error("expected a VMServiceScrape for victoriametrics")
+	}
+	rule := readGenerated(t, chart, "templates", "prometheusrule.yaml")
+	if !bytes.Contains(rule, []byte("kind: VMRule")) {
+		t.Error("expected a VMRule for victoriametrics")
+	}
+	for _, f := range []string{filepath.Join("templates", "grafana-dashboard.yaml"), filepath.Join("dashboards", "testapp.json")} {
+		if _, err := os.Stat(filepath.Join(chart, f)); err != nil {
+			t.Errorf("%s not created", f)
+		}
+	}
+}
+
+func TestGenerateHelm_CanaryArgoRollouts(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.Strategy = "canary"
+	if err := generateHelm(dir, d, false, false, false); err != nil {
+		t.Fatalf("generateHelm (canary) error: %v", err)
+	}
+	tmpls := filepath.Join(dir, "charts", "testapp", "templates")
+	for _, f := range []string{"rollout.yaml", "analysistemplate.yaml"} {
+		if _, err := os.Stat(filepath.Join(tmpls, f)); err != nil {
+			t.Errorf("%s not created", f)
+		}
+	}
+	hpa := readGenerated(t, tmpls, "hpa.yaml")
+	if !bytes.Contains(hpa, []byte("kind: Rollout")) {
+		t.Error("expected HPA to scale the Rollout")
+	}
+}
+
+func TestGenerateHelm_DomainTLS(t *testing.T) {
+	dir := t.TempDir()
+	d := testData()
+	d.Domain, d.ACMEEmail = "acme.io", "admin@acme.io"
+	if err := generateHelm(dir, d, false, false, false); err != nil {
+		t.Fatalf("generateHelm error: %v", err)
+	}
+	chart := filepath.Join(dir, "charts", "testapp")
+	if _, err := os.Stat(filepath.Join(chart, "templates", "certificate.yaml")); err != nil {
+		t.Error("certificate.yaml not created")
+	}
+	values := readGenerated(t, chart, "values.yaml")
+	assertContains(t, "values.yaml", values, "host: testapp.acme.io", "tls: true", "email: admin@acme.io")
+}
+
+func TestGenerateHelm_NoDeps(t *testing.T) {
+	dir := t.TempDir()
+	if err := generateHelm(dir, testData(), false, false, false); err != nil {
+		t.Fatalf("generateHelm error: %v", err)
+	}
+	content := readGenerated(t, dir, "charts", "testapp", "Chart.yaml")
+	if bytes.Contains(content, []byte("dependencies:")) {
+		t.Error("Chart.yaml should not declare dependencies without --with-deps")
+	}
+}
+
+func TestGenerateHelm_DefaultsMatchSchema(t *testing.T) {
+	for _, tc := range []struct{ strategy, controller string }{
+		{"rolling", ""},
+		{"canary", "argo-rollouts"},
+		{"blue-green", "argo-rollouts"},
+		{"canary", "flagger"},
+		{"blue-green", "flagger"},
+	} {
+		t.Run(tc.strategy+"/"+tc.controller, func(t *testing.T) {
+			dir := t.TempDir()
+			d := testData()
+			d.Strategy, d.Rollouts = tc.strategy, tc.controller
+			d.Domain = "example.com"
+			if err := generateHelm(dir, d, true, false, false); err != nil {
+				t.Fatalf("generateHelm error: %v", err)
+			}
+			chart := filepath.Join(dir, "charts", "testapp")
+			var values interface{}
+			var schema map[string]interface{}
+			raw := readGenerated(t, chart, "values.yaml")
+			if err := yaml.Unmarshal(raw, &values); err != nil {
+				t.Fatalf("values.yaml is not valid YAML: %v", err)
+			}
+			raw = readGenerated(t, chart, "values.schema.json")
+			if err := json.Unmarshal(raw, &schema); err != nil {
+				t.Fatalf("values.schema.json is not valid JSON: %v", err)
+			}
+			for _, problem := range schemaProblems(schema, values, "") {
+				t.Error(problem)
+			}
+		})
+	}
+}
+
+// ─── Helpers ─────────────────────────────────────────────────────────────────
+
+// schemaProblems validates v against the subset of JSON Schema that
+// helmValuesSchema emits: type, properties, additionalProperties and items.
+func schemaProblems(schema map[string]interface{}, v interface{}, path string) []string {
+	if v == nil {
+		return nil // Helm drops null values before validating
+	}
+	var types []string
+	switch t := schema["type"].(type) {
+	case string:
+		types = []string{t}
+	case []interface{}:
+		for _, s := range t {
+			types = append(types, s.(string))
+		}
+	}
+	if len(types) > 0 {
+		ok := false
+		for _, typ := range types {
+			switch v.(type) {
+			case map[string]interface{}:
+				ok = ok || typ == "object"
+			case []interface{}:
+				ok = ok || typ == "array"
+			case string:
+				ok = ok || typ == "string"
+			case bool:
+				ok = ok || typ == "boolean"
+			case int:
+				ok = ok || typ == "integer" || typ == "number"
+			case float64:
+				ok = ok || typ == "number"
+			}
+		}
+		if !ok {
+			return []string{fmt.Sprintf("%s: %T is not %v", path, v, types)}
+		}
+	}
+	var problems []string
+	switch val := v.(type) {
+	case map[string]interface{}:
+		props, _ := schema["properties"].(map[string]interface{})
+		for k, child := range val {
+			sub, ok := props[k].(map[string]interface{})
+			if !ok {
+				if schema["additionalProperties"] == false {
+					problems = append(problems, fmt.Sprintf("%s: additional property %s is not allowed", path, k))
+				}
+				continue
+			}
+			problems = append(problems, schemaProblems(sub, child, path+"."+k)...)
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range val {
+				problems = append(problems, schemaProblems(items, item, fmt.Sprintf("%s.%d", path, i))...)
+			}
+		}
+	}
+	return problems
+}
+
+// captureStdout returns what fn prints.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	old := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	defer func() { os.Stdout = old }()
+	done := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		io.Copy(&buf, r)
+		done <- buf.String()
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+// readGenerated reads a file a generator wrote under dir, failing the test if
+// it is missing.
+func readGenerated(t *testing.T, dir string, elem ...string) []byte {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(append([]string{dir}, elem...)...))
+	if err != nil {
+		t.Fatalf("%s not written: %v", filepath.Join(elem...), err)
+	}
+	return content
+}
+
+// assertContains reports each of want that name's content lacks.
+func assertContains(t *testing.T, name string, content []byte, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		if !bytes.Contains(content, []byte(w)) {
+			t.Errorf("%s missing %q", name, w)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 
 	"github.com/Harsh-BH/Exo/internal/config"
 	"github.com/Harsh-BH/Exo/internal/detector"
@@ -85,6 +86,11 @@ var initCmd = &cobra.Command{
 			}
 		}
 
+		monitoring, err := config.ParseMonitoring(projectData.Monitoring)
+		if err != nil {
+			return err
+		}
+
 		fmt.Printf("\nGenerating assets for '%s'...\n\n", projectData.Name)
 
 		data := config.TemplateData{
@@ -92,39 +98,35 @@ var initCmd = &cobra.Command{
 			Language:   projectData.Language,
 			Provider:   projectData.Provider,
 			CI:         projectData.CI,
-			Monitoring: projectData.Monitoring,
+			Monitoring: monitoring,
 			DB:         projectData.DB,
 			Port:       8080,
 		}
 
 		// ── 1. Dockerfile ──────────────────────────────────────────────────────
-		dockerTemplateMap := map[string]string{
-			"go":     "dockerfile.tmpl",
-			"node":   "node.tmpl",
-			"python": "python.tmpl",
-		}
-		if tmplFile, ok := dockerTemplateMap[projectData.Language]; ok {
-			tmplPath := filepath.Join("templates", "docker", tmplFile)
-			outPath := filepath.Join(cwd, "Dockerfile")
-			if err := renderer.RenderTemplate(tmplPath, outPath, data); err != nil {
+		if _, ok := dockerTemplates[projectData.Language]; ok {
+			d, err := newDockerData(cwd, data)
+			if err == nil {
+				err = renderFiles(cwd, dockerFiles(cwd, d), false, true)
+			}
+			if err != nil {
 				printErr(fmt.Sprintf("Dockerfile: %v", err))
 			} else {
-				printOK(fmt.Sprintf("Dockerfile (%s)", projectData.Language))
+				printOK(fmt.Sprintf("Dockerfile (%s) + .dockerignore", projectData.Language))
 			}
 		}
 
 		// ── 2. Infrastructure ──────────────────────────────────────────────────
 		if projectData.Provider != "none" {
 			infraDir := filepath.Join(cwd, "infra", projectData.Provider)
-			tmplDir := filepath.Join("templates", "terraform", projectData.Provider)
+			files, err := infraFiles(filepath.Join("terraform", projectData.Provider), infraDir, newInfraData(cwd, data))
+			if err != nil {
+				return err
+			}
 			allOK := true
-			for _, f := range []string{"main.tf", "variables.tf", "provider.tf"} {
-				if err := renderer.RenderTemplate(
-					filepath.Join(tmplDir, f+".tmpl"),
-					filepath.Join(infraDir, f),
-					data,
-				); err != nil {
-					printErr(fmt.Sprintf("infra/%s/%s: %v", projectData.Provider, f, err))
+			for _, f := range files {
+				if err := renderer.RenderTemplate(filepath.Join("templates", f.tmpl), f.out, f.data); err != nil {
+					printErr(fmt.Sprintf("infra/%s/%s: %v", projectData.Provider, filepath.Base(f.out), err))
 					allOK = false
 				}
 			}
@@ -134,64 +136,40 @@ var initCmd = &cobra.Command{
 		}
 
 		// ── 3. CI/CD ───────────────────────────────────────────────────────────
-		switch projectData.CI {
-		case "github-actions":
-			ciDir := filepath.Join(cwd, ".github", "workflows")
-			if err := renderer.RenderTemplate(
-				filepath.Join("templates", "ci", "github-actions.tmpl"),
-				filepath.Join(ciDir, "go.yml"),
-				data,
-			); err != nil {
-				printErr(fmt.Sprintf("GitHub Actions: %v", err))
+		if projectData.CI != "" && projectData.CI != "none" {
+			if written, err := writeCI(cwd, newCIData(cwd, data), false, true); err != nil {
+				printErr(fmt.Sprintf("%s: %v", ciLabel(projectData.CI), err))
 			} else {
-				printOK("GitHub Actions → .github/workflows/go.yml")
-			}
-		case "gitlab-ci":
-			if err := renderer.RenderTemplate(
-				filepath.Join("templates", "ci", "gitlab-ci.tmpl"),
-				filepath.Join(cwd, ".gitlab-ci.yml"),
-				data,
-			); err != nil {
-				printErr(fmt.Sprintf("GitLab CI: %v", err))
-			} else {
-				printOK("GitLab CI → .gitlab-ci.yml")
+				printOK(fmt.Sprintf("%s → %s", ciLabel(projectData.CI), strings.Join(written, ", ")))
 			}
 		}
 
 		// ── 4. Monitoring ──────────────────────────────────────────────────────
-		if projectData.Monitoring == "prometheus" {
-			monDir := filepath.Join(cwd, "monitoring")
-			files := map[string]string{
-				"prometheus.yml":                filepath.Join("templates", "monitoring", "prometheus.tmpl"),
-				"docker-compose.monitoring.yml": filepath.Join("templates", "monitoring", "docker-compose.monitoring.tmpl"),
+		if monitoring.Enabled() {
+			files, err := monitoringFiles(cwd, data)
+			allOK := err == nil
+			if err != nil {
+				printErr(fmt.Sprintf("monitoring: %v", err))
 			}
-			allOK := true
-			for outFile, tmplPath := range files {
-				if err := renderer.RenderTemplate(tmplPath, filepath.Join(monDir, outFile), data); err != nil {
-					printErr(fmt.Sprintf("monitoring/%s: %v", outFile, err))
+			for _, f := range files {
+				if err := renderer.RenderTemplate(filepath.Join("templates", f.tmpl), f.out, f.data); err != nil {
+					printErr(fmt.Sprintf("monitoring/%s: %v", filepath.Base(f.out), err))
 					allOK = false
 				}
 			}
 			if allOK {
-				printOK("Prometheus + Grafana → monitoring/")
+				printOK(monitoringLabel(monitoring) + " → monitoring/")
 			}
 		}
 
 		// ── 5. Database ────────────────────────────────────────────────────────
 		if projectData.DB != "" && projectData.DB != "none" {
-			dbTmplMap := map[string]string{
-				"postgres": "postgres.tmpl",
-				"mysql":    "mysql.tmpl",
-				"mongo":    "mongo.tmpl",
-				"redis":    "redis.tmpl",
-			}
-			if tmplFile, ok := dbTmplMap[projectData.DB]; ok {
-				outPath := filepath.Join(cwd, fmt.Sprintf("docker-compose.%s.yml", projectData.DB))
-				if err := renderer.RenderTemplate(filepath.Join("templates", "db", tmplFile), outPath, data); err != nil {
-					printErr(fmt.Sprintf("db: %v", err))
-				} else {
-					printOK(fmt.Sprintf("%s → docker-compose.%s.yml", projectData.DB, projectData.DB))
-				}
+			if f, err := dbComposeFile(cwd, data); err != nil {
+				printErr(fmt.Sprintf("db: %v", err))
+			} else if err := renderer.RenderTemplate(filepath.Join("templates", f.tmpl), f.out, f.data); err != nil {
+				printErr(fmt.Sprintf("db: %v", err))
+			} else {
+				printOK(fmt.Sprintf("%s → docker-compose.%s.yml", projectData.DB, projectData.DB))
 			}
 		}
 
@@ -201,7 +179,7 @@ var initCmd = &cobra.Command{
 			Language:   projectData.Language,
 			Provider:   projectData.Provider,
 			CI:         projectData.CI,
-			Monitoring: projectData.Monitoring,
+			Monitoring: monitoring,
 			DB:         projectData.DB,
 			Port:       8080,
 		}
@@ -223,8 +201,8 @@ func init() {
 	initCmd.Flags().String("name", "", "Project name (required in non-interactive mode)")
 	initCmd.Flags().String("lang", "go", "Language (go, node, python)")
 	initCmd.Flags().String("provider", "none", "Cloud provider (aws, gcp, azure, none)")
-	initCmd.Flags().String("ci", "none", "CI/CD tool (github-actions, gitlab-ci, none)")
-	initCmd.Flags().String("monitoring", "none", "Monitoring stack (prometheus, none)")
+	initCmd.Flags().String("ci", "none", "CI/CD tool (github-actions, gitlab-ci, jenkins, circleci, azure-pipelines, bitbucket-pipelines, woodpecker, none)")
+	initCmd.Flags().String("monitoring", "none", "Monitoring stack: none, or a comma-separated set of prometheus|victoriametrics, loki, tempo|jaeger, otel-collector")
 	initCmd.Flags().String("db", "none", "Database (postgres, mysql, mongo, redis, none)")
 	initCmd.Flags().String("from-git", "", "Clone a remote git repository before running the wizard (e.g. https://github.com/org/repo)")
 }
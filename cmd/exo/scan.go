@@ -61,8 +61,10 @@ var scanCmd = &cobra.Command{
   - AWS access/secret keys
   - Private keys (RSA, EC, DSA)
   - Hardcoded passwords and API keys
-  - GitHub, Slack, Google tokens`,
-	Run: func(cmd *cobra.Command, args []string) {
+  - GitHub, Slack, Google tokens
+
+Use --fail in CI to exit non-zero when anything is found.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		cwd, _ := os.Getwd()
 
 		okStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("82")).Bold(true)
@@ -144,7 +146,7 @@ var scanCmd = &cobra.Command{
 			fmt.Printf("  %s  No secrets detected in %s\n", okStyle.Render("✓"), cwd)
 			fmt.Println()
 			fmt.Println(lineStyle.Render("  Tip: Always use environment variables for secrets. See 'exo gen env'."))
-			return
+			return nil
 		}
 
 		fmt.Printf("  %s  Found %d potential secret(s):\n\n", warnStyle.Render("⚠"), len(findings))
@@ -163,9 +165,14 @@ var scanCmd = &cobra.Command{
 		}
 		fmt.Println(lineStyle.Render("  Fix: Move secrets to .env and reference via environment variables."))
 		fmt.Println(lineStyle.Render("  Run 'exo gen env' to generate a .env.example template."))
+		if fail, _ := cmd.Flags().GetBool("fail"); fail {
+			return fmt.Errorf("%d potential secret(s) found", len(findings))
+		}
+		return nil
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(scanCmd)
+	scanCmd.Flags().Bool("fail", false, "Exit non-zero when potential secrets are found (for CI)")
 }
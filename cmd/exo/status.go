@@ -30,6 +30,7 @@ var statusGroups = []statusGroup{
 		entries: []statusEntry{
 			{"Dockerfile", "Dockerfile"},
 			{"docker-compose", "docker-compose.yml"},
+			{"Compose override", "compose.override.yml"},
 		},
 	},
 	{
@@ -37,6 +38,15 @@ var statusGroups = []statusGroup{
 		entries: []statusEntry{
 			{"GitHub Actions", filepath.Join(".github", "workflows")},
 			{"GitLab CI", ".gitlab-ci.yml"},
+			{"Jenkins", "Jenkinsfile"},
+			{"CircleCI", ".circleci"},
+			{"Azure Pipelines", "azure-pipelines.yml"},
+			{"Bitbucket Pipelines", "bitbucket-pipelines.yml"},
+			{"Woodpecker CI", ".woodpecker.yml"},
+			{"GoReleaser", ".goreleaser.yaml"},
+			{"semantic-release", ".releaserc.json"},
+			{"Changesets", ".changeset"},
+			{"release-please", "release-please-config.json"},
 		},
 	},
 	{
@@ -44,6 +54,10 @@ var statusGroups = []statusGroup{
 		entries: []statusEntry{
 			{"Manifests", "k8s"},
 			{"Helm chart", "charts"},
+			{"GitOps", "gitops"},
+			{"Dev loop cluster", "devloop"},
+			{"Tiltfile", "Tiltfile"},
+			{"Skaffold", "skaffold.yaml"},
 		},
 	},
 	{
@@ -66,8 +80,8 @@ var statusGroups = []statusGroup{
 	{
 		title: "Monitoring",
 		entries: []statusEntry{
-			{"Prometheus / Grafana", "monitoring"},
-			{"Alert rules", "alerts.yml"},
+			{"Monitoring stack", "monitoring"},
+			{"Alert rules", "monitoring/rules/alerts.yml"},
 			{"Grafana dashboard", "grafana_dashboard.json"},
 		},
 	},
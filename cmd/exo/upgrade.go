@@ -38,12 +38,17 @@ var upgradeCmd = &cobra.Command{
 			return nil
 		}
 
+		monitoring, err := config.ParseMonitoring(projectData.Monitoring)
+		if err != nil {
+			return err
+		}
+
 		newCfg := &config.ExoConfig{
 			Name:       projectData.Name,
 			Language:   projectData.Language,
 			Provider:   projectData.Provider,
 			CI:         projectData.CI,
-			Monitoring: projectData.Monitoring,
+			Monitoring: monitoring,
 		}
 		if err := config.Save(cwd, newCfg); err != nil {
 			fmt.Printf("Warning: could not save config: %v\n", err)
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
@@ -18,7 +19,10 @@ var validateCmd = &cobra.Command{
 Available targets:
   infra    Run terraform fmt -check and terraform validate
   k8s      Run kubectl apply --dry-run=client on k8s/ manifests
-  docker   Run docker build --check on Dockerfile (--security runs Trivy)
+           (builds k8s/base and k8s/overlays/* first when using Kustomize)
+  helm     Run helm lint and helm template on charts/*
+  docker   Check .dockerignore and run docker build --check on Dockerfile
+           (--security runs Trivy)
   all      Run all validations`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -31,14 +35,17 @@ Available targets:
 			validateInfra(cwd)
 		case "k8s":
 			validateK8s(cwd)
+		case "helm":
+			validateHelm(cwd)
 		case "docker":
 			validateDocker(cwd, security)
 		case "all":
 			validateInfra(cwd)
 			validateK8s(cwd)
+			validateHelm(cwd)
 			validateDocker(cwd, security)
 		default:
-			return fmt.Errorf("unknown target: %s\n\nAvailable: infra, k8s, docker, all", target)
+			return fmt.Errorf("unknown target: %s\n\nAvailable: infra, k8s, helm, docker, all", target)
 		}
 		return nil
 	},
@@ -89,23 +96,10 @@ func validateInfra(cwd string) {
 			continue
 		}
 		found = true
-		fmt.Printf("\n  Checking infra/%s/\n", p)
-
-		// terraform fmt -check
-		if out, err := runCheck("terraform", "-chdir="+dir, "fmt", "-check"); err != nil {
-			valErr(fmt.Sprintf("fmt: formatting issues found\n%s", out))
-		} else {
-			valOK("fmt: code is properly formatted")
-		}
-
-		// terraform init -backend=false (needed before validate)
-		exec.Command("terraform", "-chdir="+dir, "init", "-backend=false", "-input=false").Run() //nolint
-
-		// terraform validate
-		if out, err := runCheck("terraform", "-chdir="+dir, "validate"); err != nil {
-			valErr(fmt.Sprintf("validate: %s", out))
-		} else {
-			valOK("validate: configuration is valid")
+		validateTerraformDir(fmt.Sprintf("infra/%s/", p), dir)
+		// The state bootstrap module runs with local state, so check it too.
+		if bootstrap := filepath.Join(dir, "bootstrap"); fileExists(bootstrap) {
+			validateTerraformDir(fmt.Sprintf("infra/%s/bootstrap/", p), bootstrap)
 		}
 	}
 	if !found {
@@ -113,6 +107,29 @@ func validateInfra(cwd string) {
 	}
 }
 
+// validateTerraformDir runs fmt and validate against one Terraform root.
+// Backends are skipped on init so validation never touches remote state.
+func validateTerraformDir(label, dir string) {
+	fmt.Printf("\n  Checking %s\n", label)
+
+	// terraform fmt -check
+	if out, err := runCheck("terraform", "-chdir="+dir, "fmt", "-check"); err != nil {
+		valErr(fmt.Sprintf("fmt: formatting issues found\n%s", out))
+	} else {
+		valOK("fmt: code is properly formatted")
+	}
+
+	// terraform init -backend=false (needed before validate)
+	exec.Command("terraform", "-chdir="+dir, "init", "-backend=false", "-input=false").Run() //nolint
+
+	// terraform validate
+	if out, err := runCheck("terraform", "-chdir="+dir, "validate"); err != nil {
+		valErr(fmt.Sprintf("validate: %s", out))
+	} else {
+		valOK("validate: configuration is valid")
+	}
+}
+
 func validateK8s(cwd string) {
 	fmt.Println(valHdrStyle.Render("\n── Kubernetes Validation ──"))
 
@@ -127,6 +144,14 @@ func validateK8s(cwd string) {
 		return
 	}
 
+	if dirs := kustomizeDirs(k8sDir); len(dirs) > 0 {
+		for _, dir := range dirs {
+			rel, _ := filepath.Rel(cwd, dir)
+			validateKustomization(filepath.ToSlash(rel), dir)
+		}
+		return
+	}
+
 	out, err := runCheck("kubectl", "apply", "--dry-run=client", "-f", k8sDir)
 	if err != nil {
 		valErr(fmt.Sprintf("k8s manifests have errors:\n%s", out))
@@ -136,6 +161,106 @@ func validateK8s(cwd string) {
 	}
 }
 
+// kustomizeDirs returns k8s/base and every k8s/overlays/<env> that holds a
+// kustomization.yaml, or nil when k8s/ contains plain manifests.
+func kustomizeDirs(k8sDir string) []string {
+	var dirs []string
+	candidates := []string{filepath.Join(k8sDir, "base")}
+	if entries, err := os.ReadDir(filepath.Join(k8sDir, "overlays")); err == nil {
+		for _, e := range entries {
+			if e.IsDir() {
+				candidates = append(candidates, filepath.Join(k8sDir, "overlays", e.Name()))
+			}
+		}
+	}
+	for _, dir := range candidates {
+		if _, err := os.Stat(filepath.Join(dir, "kustomization.yaml")); err == nil {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// validateKustomization builds dir with kubectl kustomize, then dry-runs the
+// rendered output so errors are reported against the built overlay.
+func validateKustomization(label, dir string) {
+	fmt.Printf("\n  Checking %s/\n", label)
+
+	built, err := runCheck("kubectl", "kustomize", dir)
+	if err != nil {
+		valErr(fmt.Sprintf("kustomize build failed:\n%s", built))
+		return
+	}
+	valOK("kustomize build succeeded")
+
+	cmd := exec.Command("kubectl", "apply", "--dry-run=client", "-f", "-")
+	cmd.Stdin = strings.NewReader(built)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		valErr(fmt.Sprintf("rendered manifests have errors:\n%s", out))
+	} else {
+		valOK("rendered manifests are valid (dry-run passed)")
+	}
+}
+
+func validateHelm(cwd string) {
+	fmt.Println(valHdrStyle.Render("\n── Helm Validation ──"))
+
+	charts, _ := filepath.Glob(filepath.Join(cwd, "charts", "*", "Chart.yaml"))
+	if len(charts) == 0 {
+		valWarn("No charts/ directory found — run 'exo gen helm' first")
+		return
+	}
+
+	if !toolExists("helm") {
+		valWarn("helm not found — install from https://helm.sh/docs/intro/install/")
+		return
+	}
+
+	for _, chart := range charts {
+		dir := filepath.Dir(chart)
+		name := filepath.Base(dir)
+		fmt.Printf("\n  Checking charts/%s/\n", name)
+
+		if out, err := runCheck("helm", "lint", dir); err != nil {
+			valErr(fmt.Sprintf("lint: %s", out))
+		} else {
+			valOK("lint: chart is well-formed")
+		}
+
+		if out, err := runCheck("helm", "template", name, dir); err != nil {
+			valErr(fmt.Sprintf("template: %s", out))
+		} else {
+			valOK("template: chart renders successfully")
+		}
+	}
+}
+
+// checkDockerignore warns when the build context isn't filtered, or the filter
+// lets .git or .env through.
+func checkDockerignore(cwd string) {
+	raw, err := os.ReadFile(filepath.Join(cwd, ".dockerignore"))
+	if err != nil {
+		valWarn("No .dockerignore — .git, .env and dependency directories are sent with every build; run 'exo gen dockerignore'")
+		return
+	}
+	entries := map[string]bool{}
+	for _, line := range strings.Split(string(raw), "\n") {
+		entries[strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(line), "/"), "/")] = true
+	}
+	var missing []string
+	for _, p := range []string{".git", ".env"} {
+		if !entries[p] {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) > 0 {
+		valWarn(fmt.Sprintf(".dockerignore does not exclude %s", strings.Join(missing, ", ")))
+		return
+	}
+	valOK(".dockerignore excludes .git and .env")
+}
+
 func validateDocker(cwd string, security bool) {
 	fmt.Println(valHdrStyle.Render("\n── Dockerfile Validation ──"))
 
@@ -144,6 +269,7 @@ func validateDocker(cwd string, security bool) {
 		valWarn("No Dockerfile found — run 'exo gen docker' first")
 		return
 	}
+	checkDockerignore(cwd)
 
 	if !toolExists("docker") {
 		valWarn("docker not found — install from https://docs.docker.com/get-docker/")
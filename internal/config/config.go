@@ -4,23 +4,305 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
 const ConfigFileName = ".exo.yaml"
 
+// DefaultEnvironments is used when .exo.yaml does not list any environments.
+var DefaultEnvironments = []string{"dev", "staging", "prod"}
+
 // ExoConfig mirrors the wizard's ProjectData for persistence.
 type ExoConfig struct {
-	Name       string `yaml:"name"`
-	Language   string `yaml:"language"`
-	Framework  string `yaml:"framework,omitempty"`
-	Provider   string `yaml:"provider"`
-	CI         string `yaml:"ci"`
-	Monitoring string `yaml:"monitoring"`
-	DB         string `yaml:"db,omitempty"`
-	Port       int    `yaml:"port,omitempty"`
-	Registry   string `yaml:"registry,omitempty"`
+	Name       string           `yaml:"name"`
+	Language   string           `yaml:"language"`
+	Framework  string           `yaml:"framework,omitempty"`
+	Provider   string           `yaml:"provider"`
+	CI         string           `yaml:"ci"`
+	Monitoring MonitoringConfig `yaml:"monitoring"`
+	DB         string           `yaml:"db,omitempty"`
+	Port       int              `yaml:"port,omitempty"`
+	Registry   string           `yaml:"registry,omitempty"`
+	Compute    string           `yaml:"compute,omitempty"`
+
+	Environments []string        `yaml:"environments,omitempty"`
+	Domain       DomainConfig    `yaml:"domain,omitempty"`
+	Strategy     StrategyConfig  `yaml:"strategy,omitempty"`
+	Terraform    TerraformConfig `yaml:"terraform,omitempty"`
+	Pipeline     PipelineConfig  `yaml:"pipeline,omitempty"`
+	Docker       DockerConfig    `yaml:"docker,omitempty"`
+	Alerts       AlertsConfig    `yaml:"alerts,omitempty"`
+}
+
+// DockerConfig hardens the Dockerfile `exo gen docker` writes. The zero value
+// builds on alpine, runs as nonroot (uid 65532), probes /health and uses
+// BuildKit cache mounts.
+type DockerConfig struct {
+	Base         string            `yaml:"base,omitempty"`         // alpine | debian-slim | distroless | chainguard | scratch
+	User         string            `yaml:"user,omitempty"`         // runtime user, default nonroot; "root" opts out
+	Healthcheck  string            `yaml:"healthcheck,omitempty"`  // HTTP path HEALTHCHECK probes, default /health; "none" disables
+	CacheMounts  *bool             `yaml:"cache_mounts,omitempty"` // BuildKit cache mounts for dependency downloads, default true
+	Dockerignore *bool             `yaml:"dockerignore,omitempty"` // write .dockerignore next to the Dockerfile, default true
+	Pin          bool              `yaml:"pin,omitempty"`          // resolve base image digests with docker buildx imagetools
+	Digests      map[string]string `yaml:"digests,omitempty"`      // image → digest pins, e.g. alpine:3.20: sha256:…
+	Labels       map[string]string `yaml:"labels,omitempty"`       // extra OCI labels
+	Platforms    []string          `yaml:"platforms,omitempty"`    // multi-arch targets, default [linux/amd64, linux/arm64]
+}
+
+// CacheMountsEnabled reports whether BuildKit cache mounts are on (the default).
+func (c DockerConfig) CacheMountsEnabled() bool {
+	return c.CacheMounts == nil || *c.CacheMounts
+}
+
+// DockerignoreEnabled reports whether .dockerignore is written (the default).
+func (c DockerConfig) DockerignoreEnabled() bool {
+	return c.Dockerignore == nil || *c.Dockerignore
+}
+
+// DefaultPlatforms are the image targets built when docker.platforms is unset.
+var DefaultPlatforms = []string{"linux/amd64", "linux/arm64"}
+
+// PipelineConfig tunes the pipeline `exo gen ci` writes.
+type PipelineConfig struct {
+	Versions []string `yaml:"versions,omitempty"` // language versions to test on, e.g. ["1.22", "1.23"]
+}
+
+// MonitoringConfig is the observability stack: a metrics backend, plus
+// optional logs, traces and an OpenTelemetry Collector in front of them.
+// Grafana comes with any of them. In .exo.yaml it is a single value
+// (prometheus | none) or a list, e.g. [victoriametrics, loki, tempo, otel-collector].
+type MonitoringConfig struct {
+	Metrics   string // prometheus | victoriametrics
+	Logs      string // loki (shipped by promtail)
+	Traces    string // tempo | jaeger
+	Collector bool   // otel-collector receives OTLP and fans out to the backends
+}
+
+// monitoringComponents maps each accepted component to the slot it fills.
+var monitoringComponents = map[string]string{
+	"prometheus":      "metrics",
+	"victoriametrics": "metrics",
+	"loki":            "logs",
+	"tempo":           "traces",
+	"jaeger":          "traces",
+	"otel-collector":  "collector",
+}
+
+// ParseMonitoring reads a comma-separated component list, as the --monitoring
+// flag takes it. "" and "none" disable monitoring.
+func ParseMonitoring(s string) (MonitoringConfig, error) {
+	var m MonitoringConfig
+	seen := map[string]string{}
+	for _, c := range strings.Split(s, ",") {
+		c = strings.TrimSpace(c)
+		if c == "" || c == "none" {
+			continue
+		}
+		slot, ok := monitoringComponents[c]
+		if !ok {
+			return m, fmt.Errorf("unknown monitoring component %q (prometheus, victoriametrics, loki, tempo, jaeger, otel-collector)", c)
+		}
+		if prev := seen[slot]; prev != "" && prev != c {
+			return m, fmt.Errorf("monitoring lists both %s and %s; pick one %s backend", prev, c, slot)
+		}
+		seen[slot] = c
+		switch slot {
+		case "metrics":
+			m.Metrics = c
+		case "logs":
+			m.Logs = c
+		case "traces":
+			m.Traces = c
+		case "collector":
+			m.Collector = true
+		}
+	}
+	if m.Metrics == "" && m != (MonitoringConfig{}) {
+		return m, fmt.Errorf("monitoring needs a metrics backend (prometheus or victoriametrics) for Grafana to sit on")
+	}
+	return m, nil
+}
+
+// Enabled reports whether any monitoring is configured.
+func (m MonitoringConfig) Enabled() bool {
+	return m.Metrics != ""
+}
+
+// Components lists the configured components in the order they are
+// written to .exo.yaml.
+func (m MonitoringConfig) Components() []string {
+	var out []string
+	for _, c := range []string{m.Metrics, m.Logs, m.Traces} {
+		if c != "" {
+			out = append(out, c)
+		}
+	}
+	if m.Collector {
+		out = append(out, "otel-collector")
+	}
+	return out
+}
+
+// OTLPReceiver returns the component apps send OTLP to: the collector when
+// there is one, otherwise the trace backend, or "" when nothing receives it.
+func (m MonitoringConfig) OTLPReceiver() string {
+	if m.Collector {
+		return "otel-collector"
+	}
+	return m.Traces
+}
+
+func (m MonitoringConfig) String() string {
+	if !m.Enabled() {
+		return "none"
+	}
+	return strings.Join(m.Components(), ",")
+}
+
+// UnmarshalYAML accepts a single value or a list of components.
+func (m *MonitoringConfig) UnmarshalYAML(node *yaml.Node) error {
+	var list []string
+	switch node.Kind {
+	case yaml.ScalarNode:
+		list = []string{node.Value}
+	case yaml.SequenceNode:
+		if err := node.Decode(&list); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("line %d: monitoring must be a value or a list", node.Line)
+	}
+	parsed, err := ParseMonitoring(strings.Join(list, ","))
+	if err != nil {
+		return fmt.Errorf("line %d: %w", node.Line, err)
+	}
+	*m = parsed
+	return nil
+}
+
+// MarshalYAML writes a lone metrics backend (or none) as a plain value, so
+// existing configs round-trip unchanged, and anything larger as a list.
+func (m MonitoringConfig) MarshalYAML() (interface{}, error) {
+	if c := m.Components(); len(c) > 1 {
+		return c, nil
+	}
+	return m.String(), nil
+}
+
+// AlertsConfig routes alerts through Alertmanager. Receiver secrets are not
+// kept here; they come from the env contract (see AlertSecrets).
+type AlertsConfig struct {
+	Receivers    []string `yaml:"receivers,omitempty"`     // slack | pagerduty | email | webhook
+	SlackChannel string   `yaml:"slack_channel,omitempty"` // default #alerts
+	EmailTo      string   `yaml:"email_to,omitempty"`
+	EmailFrom    string   `yaml:"email_from,omitempty"` // default alertmanager@<domain>
+	SMTPHost     string   `yaml:"smtp_host,omitempty"`  // host:port
+	SMTPUser     string   `yaml:"smtp_user,omitempty"`
+}
+
+// AlertSecrets maps each receiver to the variable in .env.example holding its
+// secret. Alertmanager reads them from files named after the variable.
+var AlertSecrets = map[string]string{
+	"slack":     "SLACK_WEBHOOK_URL",
+	"pagerduty": "PAGERDUTY_ROUTING_KEY",
+	"email":     "SMTP_PASSWORD",
+	"webhook":   "ALERT_WEBHOOK_URL",
+}
+
+// Validate checks receiver names and that email has somewhere to send.
+func (a AlertsConfig) Validate() error {
+	for _, r := range a.Receivers {
+		if _, ok := AlertSecrets[r]; !ok {
+			return fmt.Errorf("unknown alert receiver %q (slack, pagerduty, email, webhook)", r)
+		}
+	}
+	if a.Has("email") && (a.EmailTo == "" || a.SMTPHost == "") {
+		return fmt.Errorf("alerts.receivers includes email; set alerts.email_to and alerts.smtp_host")
+	}
+	return nil
+}
+
+// Has reports whether receiver is configured.
+func (a AlertsConfig) Has(receiver string) bool {
+	for _, r := range a.Receivers {
+		if r == receiver {
+			return true
+		}
+	}
+	return false
+}
+
+// Notify lists the configured receivers that notify without paging.
+func (a AlertsConfig) Notify() []string {
+	var out []string
+	for _, r := range a.Receivers {
+		if r != "pagerduty" {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Secrets returns the env contract variables the configured receivers need.
+func (a AlertsConfig) Secrets() []string {
+	var out []string
+	for _, r := range a.Receivers {
+		if v, ok := AlertSecrets[r]; ok {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// StrategyConfig selects how new versions are rolled out.
+type StrategyConfig struct {
+	Type       string `yaml:"type,omitempty"`       // rolling | canary | blue-green
+	Controller string `yaml:"controller,omitempty"` // argo-rollouts | flagger
+}
+
+// UnmarshalYAML accepts the strategy type on its own (strategy: canary) as
+// well as the full mapping.
+func (s *StrategyConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*s = StrategyConfig{Type: node.Value}
+		return nil
+	}
+	type plain StrategyConfig
+	return node.Decode((*plain)(s))
+}
+
+// DomainConfig sets the DNS zone the app is served under. With Name set,
+// infra provisions the zone and ingress controller, and k8s/helm request
+// Let's Encrypt certificates through cert-manager.
+type DomainConfig struct {
+	Name  string `yaml:"name,omitempty"`  // e.g. example.com
+	Email string `yaml:"email,omitempty"` // ACME account; default admin@<name>
+}
+
+// UnmarshalYAML accepts the zone on its own (domain: example.com) as well as
+// the full mapping.
+func (d *DomainConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*d = DomainConfig{Name: node.Value}
+		return nil
+	}
+	type plain DomainConfig
+	return node.Decode((*plain)(d))
+}
+
+// TerraformConfig controls where `exo gen infra` keeps Terraform state.
+type TerraformConfig struct {
+	Backend string `yaml:"backend,omitempty"` // s3 | gcs | azurerm; empty keeps local state
+	Layout  string `yaml:"layout,omitempty"`  // directories | workspaces
+
+	Region         string `yaml:"region,omitempty"`
+	AZCount        int    `yaml:"az_count,omitempty"`
+	ClusterVersion string `yaml:"cluster_version,omitempty"`
+	NodeSize       string `yaml:"node_size,omitempty"` // instance/machine/VM type
+	NodeMin        int    `yaml:"node_min,omitempty"`
+	NodeMax        int    `yaml:"node_max,omitempty"`
 }
 
 // Save writes the config to .exo.yaml in the given directory.
@@ -65,9 +347,29 @@ type TemplateData struct {
 	Port       int    // default 8080
 	DB         string // postgres | mysql | mongo | redis | none
 	Provider   string // aws | gcp | azure | none
-	CI         string // github-actions | gitlab-ci | none
-	Monitoring string // prometheus | none
+	CI         string // github-actions | gitlab-ci | jenkins | circleci | azure-pipelines | bitbucket-pipelines | woodpecker | none
+	Monitoring MonitoringConfig
 	Registry   string // docker registry URL, optional
+	Compute    string // kubernetes | ecs-fargate | app-runner | lambda-container | cloud-run | container-apps
+	Domain     string // DNS zone the app is served under; empty uses example.com without TLS
+	ACMEEmail  string // Let's Encrypt account email, used when Domain is set
+
+	Environments []string // dev | staging | prod, used for per-env overlays
+	Strategy     string   // rolling | canary | blue-green
+	Rollouts     string   // argo-rollouts | flagger, used when Strategy is not rolling
+	StateBackend string   // s3 | gcs | azurerm, empty for local state
+	StateLayout  string   // directories | workspaces
+	CIVersions   []string // language versions the CI test job runs on; empty uses the toolchain default
+	Docker       DockerConfig
+	Alerts       AlertsConfig
+
+	// Terraform inputs; zero values are filled with per-provider defaults.
+	Region         string
+	AZCount        int
+	ClusterVersion string
+	NodeSize       string
+	NodeMin        int
+	NodeMax        int
 }
 
 // ToTemplateData converts a saved ExoConfig into a TemplateData ready for rendering.
@@ -76,6 +378,14 @@ func (c *ExoConfig) ToTemplateData() TemplateData {
 	if port == 0 {
 		port = 8080
 	}
+	envs := c.Environments
+	if len(envs) == 0 {
+		envs = DefaultEnvironments
+	}
+	email := c.Domain.Email
+	if email == "" && c.Domain.Name != "" {
+		email = "admin@" + c.Domain.Name
+	}
 	return TemplateData{
 		AppName:    c.Name,
 		Language:   c.Language,
@@ -86,5 +396,156 @@ func (c *ExoConfig) ToTemplateData() TemplateData {
 		CI:         c.CI,
 		Monitoring: c.Monitoring,
 		Registry:   c.Registry,
+		Compute:    c.Compute,
+		Domain:     c.Domain.Name,
+		ACMEEmail:  email,
+
+		Environments: envs,
+		Strategy:     c.Strategy.Type,
+		Rollouts:     c.Strategy.Controller,
+		StateBackend: c.Terraform.Backend,
+		StateLayout:  c.Terraform.Layout,
+		CIVersions:   c.Pipeline.Versions,
+		Docker:       c.Docker,
+		Alerts:       c.Alerts,
+
+		Region:         c.Terraform.Region,
+		AZCount:        c.Terraform.AZCount,
+		ClusterVersion: c.Terraform.ClusterVersion,
+		NodeSize:       c.Terraform.NodeSize,
+		NodeMin:        c.Terraform.NodeMin,
+		NodeMax:        c.Terraform.NodeMax,
+	}
+}
+
+// ImageRepository returns the app's image name, qualified with Registry when
+// one is configured.
+func (d TemplateData) ImageRepository() string {
+	if d.Registry == "" {
+		return d.AppName
+	}
+	return strings.TrimSuffix(d.Registry, "/") + "/" + d.AppName
+}
+
+// Platforms returns the image targets from docker.platforms, or
+// DefaultPlatforms.
+func (d TemplateData) Platforms() []string {
+	if len(d.Docker.Platforms) == 0 {
+		return DefaultPlatforms
+	}
+	return d.Docker.Platforms
+}
+
+// BaseDomain returns Domain, or example.com as a placeholder when unset.
+func (d TemplateData) BaseDomain() string {
+	if d.Domain == "" {
+		return "example.com"
+	}
+	return d.Domain
+}
+
+// ProdEnv returns the last configured environment, which exo treats as
+// production (plain manifests and chart defaults target it).
+func (d TemplateData) ProdEnv() string {
+	if n := len(d.Environments); n > 0 {
+		return d.Environments[n-1]
+	}
+	return "prod"
+}
+
+// ArgoRollouts reports whether an Argo Rollout owns the app's pods, taking
+// them over from the Deployment it references.
+func (d TemplateData) ArgoRollouts() bool {
+	switch d.Strategy {
+	case "canary", "blue-green":
+		return d.Rollouts == "" || d.Rollouts == "argo-rollouts"
+	}
+	return false
+}
+
+// WorkloadIdentity reports whether exo gen infra creates a cloud identity for
+// the app's pods: IRSA on EKS, Workload Identity on GKE or AKS.
+func (d TemplateData) WorkloadIdentity() bool {
+	switch d.Provider {
+	case "aws", "gcp", "azure":
+		return d.Compute == "" || d.Compute == "kubernetes"
+	}
+	return false
+}
+
+// ServiceAccountAnnotations returns the annotations that bind the app's
+// ServiceAccount in env to the identity exo gen infra creates. IDs that only
+// exist after apply, or can't be read from Registry, are left as <placeholders>.
+func (d TemplateData) ServiceAccountAnnotations(env string) map[string]string {
+	if !d.WorkloadIdentity() {
+		return nil
+	}
+	name := d.AppName + "-" + env
+	switch d.Provider {
+	case "aws":
+		account := "<aws-account-id>"
+		if d.RegistryKind() == "ecr" {
+			account = strings.SplitN(d.RegistryHost(), ".", 2)[0]
+		}
+		return map[string]string{"eks.amazonaws.com/role-arn": fmt.Sprintf("arn:aws:iam::%s:role/%s", account, name)}
+	case "gcp":
+		project := "<gcp-project-id>"
+		if parts := strings.Split(d.Registry, "/"); d.RegistryKind() == "gar" && len(parts) > 1 {
+			project = parts[1]
+		}
+		if len(name) > 30 {
+			name = name[:30]
+		}
+		return map[string]string{"iam.gke.io/gcp-service-account": fmt.Sprintf("%s@%s.iam.gserviceaccount.com", name, project)}
+	default:
+		return map[string]string{"azure.workload.identity/client-id": "<terraform output workload_client_id>"}
+	}
+}
+
+// RegistryKind classifies Registry by host so CI knows how to log in:
+// ecr | gar | acr | ghcr | gitlab | docker, or "" when no registry is set.
+func (d TemplateData) RegistryKind() string {
+	host := strings.SplitN(d.Registry, "/", 2)[0]
+	switch {
+	case host == "":
+		return ""
+	case strings.Contains(host, ".dkr.ecr."):
+		return "ecr"
+	case strings.HasSuffix(host, "-docker.pkg.dev"):
+		return "gar"
+	case strings.HasSuffix(host, ".azurecr.io"):
+		return "acr"
+	case host == "ghcr.io":
+		return "ghcr"
+	case host == "registry.gitlab.com":
+		return "gitlab"
+	default:
+		return "docker"
+	}
+}
+
+// RegistryHost returns the host part of Registry.
+func (d TemplateData) RegistryHost() string {
+	return strings.SplitN(d.Registry, "/", 2)[0]
+}
+
+// ACRName returns the Azure Container Registry name exo provisions: the app
+// name reduced to lowercase alphanumerics (the only characters ACR allows).
+func (d TemplateData) ACRName() string {
+	return Alphanumeric(d.AppName, 47) + "acr"
+}
+
+// Alphanumeric lowercases s, drops everything but a-z and 0-9, and truncates
+// the result to max characters. Azure resource names need this.
+func Alphanumeric(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, strings.ToLower(s))
+	if len(s) > max {
+		s = s[:max]
 	}
+	return s
 }
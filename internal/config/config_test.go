@@ -0,0 +1,200 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestRegistryKind(t *testing.T) {
+	tests := []struct {
+		registry string
+		want     string
+	}{
+		{"", ""},
+		{"123456789012.dkr.ecr.us-east-1.amazonaws.com", "ecr"},
+		{"us-central1-docker.pkg.dev/my-project/app", "gar"},
+		{"myacr.azurecr.io", "acr"},
+		{"ghcr.io/acme", "ghcr"},
+		{"registry.gitlab.com/acme", "gitlab"},
+		{"quay.io/acme", "docker"},
+	}
+	for _, tt := range tests {
+		d := TemplateData{AppName: "app", Registry: tt.registry}
+		if got := d.RegistryKind(); got != tt.want {
+			t.Errorf("RegistryKind(%q) = %q, want %q", tt.registry, got, tt.want)
+		}
+	}
+}
+
+func TestImageRepository(t *testing.T) {
+	tests := []struct {
+		registry string
+		want     string
+	}{
+		{"", "app"},
+		{"ghcr.io/acme", "ghcr.io/acme/app"},
+		{"ghcr.io/acme/", "ghcr.io/acme/app"},
+	}
+	for _, tt := range tests {
+		d := TemplateData{AppName: "app", Registry: tt.registry}
+		if got := d.ImageRepository(); got != tt.want {
+			t.Errorf("ImageRepository(%q) = %q, want %q", tt.registry, got, tt.want)
+		}
+	}
+}
+
+func TestACRName(t *testing.T) {
+	if got := (TemplateData{AppName: "My-App_2"}).ACRName(); got != "myapp2acr" {
+		t.Errorf("ACRName = %q, want myapp2acr", got)
+	}
+}
+
+func TestToTemplateData_Domain(t *testing.T) {
+	cfg := ExoConfig{Name: "app", Domain: DomainConfig{Name: "acme.io"}}
+	d := cfg.ToTemplateData()
+	if d.ACMEEmail != "admin@acme.io" {
+		t.Errorf("ACMEEmail = %q, want admin@acme.io", d.ACMEEmail)
+	}
+	if got := d.BaseDomain(); got != "acme.io" {
+		t.Errorf("BaseDomain = %q, want acme.io", got)
+	}
+	if got := (TemplateData{}).BaseDomain(); got != "example.com" {
+		t.Errorf("BaseDomain without a domain = %q, want example.com", got)
+	}
+}
+
+func TestDockerConfigDefaults(t *testing.T) {
+	var c DockerConfig
+	if !c.CacheMountsEnabled() || !c.DockerignoreEnabled() {
+		t.Error("cache mounts and .dockerignore should default to on")
+	}
+	off := false
+	c = DockerConfig{CacheMounts: &off, Dockerignore: &off}
+	if c.CacheMountsEnabled() || c.DockerignoreEnabled() {
+		t.Error("explicit false should turn cache mounts and .dockerignore off")
+	}
+	if got := (TemplateData{}).Platforms(); len(got) != 2 {
+		t.Errorf("Platforms = %v, want the defaults", got)
+	}
+	cfg := ExoConfig{Docker: DockerConfig{Platforms: []string{"linux/amd64"}}}
+	if got := cfg.ToTemplateData().Platforms(); len(got) != 1 || got[0] != "linux/amd64" {
+		t.Errorf("Platforms = %v, want [linux/amd64]", got)
+	}
+}
+
+func TestServiceAccountAnnotations(t *testing.T) {
+	tests := []struct {
+		provider, registry, key, want string
+	}{
+		{"aws", "123456789012.dkr.ecr.us-east-1.amazonaws.com", "eks.amazonaws.com/role-arn", "arn:aws:iam::123456789012:role/app-prod"},
+		{"aws", "", "eks.amazonaws.com/role-arn", "arn:aws:iam::<aws-account-id>:role/app-prod"},
+		{"gcp", "us-central1-docker.pkg.dev/acme-prod/app", "iam.gke.io/gcp-service-account", "app-prod@acme-prod.iam.gserviceaccount.com"},
+	}
+	for _, tt := range tests {
+		d := TemplateData{AppName: "app", Provider: tt.provider, Registry: tt.registry}
+		if got := d.ServiceAccountAnnotations("prod")[tt.key]; got != tt.want {
+			t.Errorf("%s/%q: %s = %q, want %q", tt.provider, tt.registry, tt.key, got, tt.want)
+		}
+	}
+	if got := (TemplateData{AppName: "app", Provider: "aws", Compute: "ecs-fargate"}).ServiceAccountAnnotations("prod"); got != nil {
+		t.Errorf("expected no annotations off Kubernetes, got %v", got)
+	}
+}
+
+func TestParseMonitoring(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    MonitoringConfig
+		wantErr bool
+	}{
+		{"", MonitoringConfig{}, false},
+		{"none", MonitoringConfig{}, false},
+		{"prometheus", MonitoringConfig{Metrics: "prometheus"}, false},
+		{"victoriametrics, loki, jaeger, otel-collector", MonitoringConfig{"victoriametrics", "loki", "jaeger", true}, false},
+		{"prometheus,victoriametrics", MonitoringConfig{}, true},
+		{"loki,tempo", MonitoringConfig{}, true},
+		{"datadog", MonitoringConfig{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMonitoring(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMonitoring(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseMonitoring(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMonitoringConfigYAML(t *testing.T) {
+	var cfg ExoConfig
+	if err := yaml.Unmarshal([]byte("monitoring: [prometheus, tempo, otel-collector]\n"), &cfg); err != nil {
+		t.Fatalf("unmarshal list: %v", err)
+	}
+	if want := (MonitoringConfig{Metrics: "prometheus", Traces: "tempo", Collector: true}); cfg.Monitoring != want {
+		t.Errorf("monitoring = %+v, want %+v", cfg.Monitoring, want)
+	}
+	if got := cfg.Monitoring.OTLPReceiver(); got != "otel-collector" {
+		t.Errorf("OTLPReceiver = %q, want otel-collector", got)
+	}
+
+	out, _ := yaml.Marshal(ExoConfig{Monitoring: MonitoringConfig{Metrics: "prometheus"}})
+	var back ExoConfig
+	if err := yaml.Unmarshal(out, &back); err != nil || back.Monitoring.Metrics != "prometheus" {
+		t.Errorf("round trip of %q = %+v, %v", out, back.Monitoring, err)
+	}
+	if err := yaml.Unmarshal([]byte("monitoring: loki\n"), &cfg); err == nil {
+		t.Error("expected logs without a metrics backend to be rejected")
+	}
+}
+
+func TestAlertsConfigValidate(t *testing.T) {
+	tests := []struct {
+		alerts  AlertsConfig
+		wantErr bool
+	}{
+		{AlertsConfig{}, false},
+		{AlertsConfig{Receivers: []string{"slack", "pagerduty", "webhook"}}, false},
+		{AlertsConfig{Receivers: []string{"email"}, EmailTo: "oncall@example.com", SMTPHost: "smtp.example.com:587"}, false},
+		{AlertsConfig{Receivers: []string{"email"}, EmailTo: "oncall@example.com"}, true},
+		{AlertsConfig{Receivers: []string{"opsgenie"}}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.alerts.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%+v) error = %v, wantErr %v", tt.alerts, err, tt.wantErr)
+		}
+	}
+	a := AlertsConfig{Receivers: []string{"pagerduty", "slack"}}
+	if got := a.Notify(); len(got) != 1 || got[0] != "slack" {
+		t.Errorf("Notify = %v, want [slack]", got)
+	}
+	if got := a.Secrets(); len(got) != 2 || got[0] != "PAGERDUTY_ROUTING_KEY" {
+		t.Errorf("Secrets = %v", got)
+	}
+}
+
+func TestStrategyConfigYAML(t *testing.T) {
+	for _, doc := range []string{"strategy: canary\n", "strategy:\n  type: canary\n"} {
+		var cfg ExoConfig
+		if err := yaml.Unmarshal([]byte(doc), &cfg); err != nil {
+			t.Fatalf("unmarshal %q: %v", doc, err)
+		}
+		if cfg.Strategy.Type != "canary" {
+			t.Errorf("%q: strategy type = %q, want canary", doc, cfg.Strategy.Type)
+		}
+	}
+}
+
+func TestDomainConfigYAML(t *testing.T) {
+	for _, doc := range []string{"domain: example.com\n", "domain:\n  name: example.com\n"} {
+		var cfg ExoConfig
+		if err := yaml.Unmarshal([]byte(doc), &cfg); err != nil {
+			t.Fatalf("unmarshal %q: %v", doc, err)
+		}
+		if cfg.Domain.Name != "example.com" {
+			t.Errorf("%q: domain name = %q, want example.com", doc, cfg.Domain.Name)
+		}
+	}
+}
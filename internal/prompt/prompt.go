@@ -148,10 +148,16 @@ func initialModel() model {
 	ciList := newList("Select CI/CD Tool", []list.Item{
 		item{"github-actions", "GitHub Actions"},
 		item{"gitlab-ci", "GitLab CI"},
+		item{"jenkins", "Jenkins (declarative Jenkinsfile)"},
+		item{"circleci", "CircleCI"},
+		item{"azure-pipelines", "Azure Pipelines"},
+		item{"bitbucket-pipelines", "Bitbucket Pipelines"},
+		item{"woodpecker", "Woodpecker CI"},
 		item{"none", "Skip CI/CD generation"},
 	})
 	monitoringList := newList("Select Monitoring Stack", []list.Item{
 		item{"prometheus", "Prometheus + Grafana"},
+		item{"prometheus,loki,tempo,otel-collector", "Metrics, logs and traces behind an OpenTelemetry Collector"},
 		item{"none", "Skip monitoring setup"},
 	})
 
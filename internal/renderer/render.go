@@ -1,6 +1,7 @@
 package renderer
 
 import (
+	"bytes"
 	"fmt"
 	"io/fs"
 	"os"
@@ -15,6 +16,49 @@ import (
 // It first tries to read from the embedded FS; if not found it falls back to disk.
 // templatePath should use forward slashes (e.g. "docker/dockerfile.tmpl").
 func RenderTemplate(templatePath string, outputPath string, data interface{}) error {
+	tmpl, err := loadTemplate(templatePath)
+	if err != nil {
+		return err
+	}
+
+	// Ensure output directory exists
+	outputDir := filepath.Dir(outputPath)
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory %s: %w", outputDir, err)
+	}
+
+	// Create the output file
+	outputFile, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create output file %s: %w", outputPath, err)
+	}
+	defer outputFile.Close()
+
+	// Execute the template
+	if err := tmpl.Execute(outputFile, data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return nil
+}
+
+// RenderToString renders a template into memory without writing any file.
+// Template lookup follows the same order as RenderTemplate.
+func RenderToString(templatePath string, data interface{}) (string, error) {
+	tmpl, err := loadTemplate(templatePath)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// loadTemplate reads and parses templatePath from the embedded FS, then disk,
+// then ~/.exo/templates/.
+func loadTemplate(templatePath string) (*template.Template, error) {
 	// Normalise to forward slashes for embed.FS compatibility
 	normalised := filepath.ToSlash(templatePath)
 
@@ -32,7 +76,7 @@ func RenderTemplate(templatePath string, outputPath string, data interface{}) er
 			remotePath := filepath.Join(home, ".exo", "templates", embedPath)
 			tmplContent, err = os.ReadFile(remotePath)
 			if err != nil {
-				return fmt.Errorf("failed to read template %s: %w", templatePath, err)
+				return nil, fmt.Errorf("failed to read template %s: %w", templatePath, err)
 			}
 		}
 	}
@@ -40,28 +84,9 @@ func RenderTemplate(templatePath string, outputPath string, data interface{}) er
 	// Parse the template
 	tmpl, err := template.New(filepath.Base(templatePath)).Parse(string(tmplContent))
 	if err != nil {
-		return fmt.Errorf("failed to parse template %s: %w", templatePath, err)
-	}
-
-	// Ensure output directory exists
-	outputDir := filepath.Dir(outputPath)
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory %s: %w", outputDir, err)
-	}
-
-	// Create the output file
-	outputFile, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("failed to create output file %s: %w", outputPath, err)
-	}
-	defer outputFile.Close()
-
-	// Execute the template
-	if err := tmpl.Execute(outputFile, data); err != nil {
-		return fmt.Errorf("failed to execute template: %w", err)
+		return nil, fmt.Errorf("failed to parse template %s: %w", templatePath, err)
 	}
-
-	return nil
+	return tmpl, nil
 }
 
 // RenderTemplateString renders a template from a raw string (not a file path).
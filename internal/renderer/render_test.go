@@ -170,3 +170,19 @@ func TestRenderTemplateString_CreatesSubdirs(t *testing.T) {
 		t.Errorf("expected file at %s, got: %v", outPath, statErr)
 	}
 }
+
+// ─── RenderToString ───────────────────────────────────────────────────────────
+
+func TestRenderToString(t *testing.T) {
+	tempDir := t.TempDir()
+	tmplPath := filepath.Join(tempDir, "test.tmpl")
+	os.WriteFile(tmplPath, []byte("port={{.Port}}"), 0644)
+
+	got, err := RenderToString(tmplPath, struct{ Port int }{Port: 9000})
+	if err != nil {
+		t.Fatalf("RenderToString() error = %v", err)
+	}
+	if got != "port=9000" {
+		t.Errorf("RenderToString() = %q, want %q", got, "port=9000")
+	}
+}